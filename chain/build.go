@@ -0,0 +1,124 @@
+package chain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/errors"
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/request_generator"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/variables"
+)
+
+// BuildRequest generates the request for a step's operation from the OAS
+// examples, then applies the step's path params, query, headers and body with
+// placeholders resolved from vars and lookup.
+func BuildRequest(oas oas_struct.OAS, chosenServerIndex int, step Step, vars Variables, lookup variables.Lookup) (request_sender.Request, error) {
+	op, err := step.Operation(oas)
+	if err != nil {
+		return request_sender.Request{}, err
+	}
+
+	httpRequest, err := request_generator.GenerateRequestForOperation(oas, chosenServerIndex, op.Path, op.Method)
+	if err != nil {
+		return request_sender.Request{}, err
+	}
+
+	requests, err := request_sender.ParseHttpRequestFile(httpRequest)
+	if err != nil {
+		return request_sender.Request{}, err
+	}
+	req := requests[0]
+	req.Name = step.Name
+
+	req.Url, err = fillPathParams(req.Url, step.PathParams, lookup)
+	if err != nil {
+		return request_sender.Request{}, err
+	}
+
+	req.Url, err = addQuery(req.Url, step.Query, lookup)
+	if err != nil {
+		return request_sender.Request{}, err
+	}
+
+	for name, value := range step.Headers {
+		req.Headers[name] = variables.Substitute(value, lookup)
+	}
+
+	if len(step.Body) > 0 {
+		body, ok := vars.Resolve(step.Body, lookup).(map[string]any)
+		if !ok {
+			return request_sender.Request{}, fmt.Errorf("body must be a map of fields to values")
+		}
+		hook := hooks_logic.HookEntry{Path: op.Path, Method: op.Method, Body: body}
+		if schema, ok := hooks_logic.RequestBodySchema(op); ok {
+			req.Body, err = hooks_logic.ModifyRequestBodyUsingSchema(hook, req.Body, oas, schema)
+		} else {
+			req.Body, err = hooks_logic.ModifyRequestBodyUsingHook(hook, req.Body)
+		}
+		if err != nil {
+			return request_sender.Request{}, err
+		}
+	}
+
+	return request_sender.ResolveVariables(req, lookup)
+}
+
+func fillPathParams(rawURL string, pathParams map[string]string, lookup variables.Lookup) (string, error) {
+	for name, value := range pathParams {
+		placeholder := "{" + name + "}"
+		if !strings.Contains(rawURL, placeholder) {
+			return "", fmt.Errorf("path parameter %q is not in %s", name, rawURL)
+		}
+		resolved, err := resolveEscaped(value, lookup)
+		if err != nil {
+			return "", err
+		}
+		rawURL = strings.ReplaceAll(rawURL, placeholder, url.PathEscape(resolved))
+	}
+
+	//{{var}} placeholders are filled later, only single-brace templates are path params
+	withoutVariables := strings.NewReplacer("{{", "", "}}", "").Replace(rawURL)
+	if missing := operations.PathParamPattern.FindStringSubmatch(withoutVariables); missing != nil {
+		return "", fmt.Errorf("path parameter %q has no value, set it in pathParams", missing[1])
+	}
+
+	return rawURL, nil
+}
+
+func addQuery(rawURL string, query map[string]string, lookup variables.Lookup) (string, error) {
+	if len(query) == 0 {
+		return rawURL, nil
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL %s: %w", rawURL, err)
+	}
+
+	values := parsedURL.Query()
+	for name, value := range query {
+		resolved, err := resolveEscaped(value, lookup)
+		if err != nil {
+			return "", err
+		}
+		values.Set(name, resolved)
+	}
+	parsedURL.RawQuery = values.Encode()
+
+	return parsedURL.String(), nil
+}
+
+// resolveEscaped resolves a value that is about to be URL-escaped, after which
+// a missing {{var}} could no longer be spotted.
+func resolveEscaped(value string, lookup variables.Lookup) (string, error) {
+	resolved := variables.Substitute(value, lookup)
+	if names := variables.Unresolved(resolved); len(names) > 0 {
+		return "", errors.NewUnresolvedVariableError("variable", names[0])
+	}
+	return resolved, nil
+}
@@ -0,0 +1,127 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alexplayer15/parmesan/jsonpath"
+	"github.com/alexplayer15/parmesan/jsonpointer"
+	"github.com/alexplayer15/parmesan/runtime_expression"
+)
+
+type extractionSource int
+
+const (
+	fromStatus extractionSource = iota
+	fromHeader
+	fromBody
+	fromJSONPath
+	fromJSONPointer
+	fromRuntimeExpression
+	fromTemplate
+)
+
+// Extraction says where a value is captured from in a response:
+//
+//	status              the status code
+//	header.Location     a response header
+//	body                the raw body
+//	$.items[0].id       a JSONPath into the JSON body
+//	/items/0/id         a JSON Pointer into the JSON body
+//
+// OAS runtime expressions such as $response.body#/id or $request.path.id, and
+// strings embedding them like /users/{$response.body#/id}, work too.
+type Extraction struct {
+	source  extractionSource
+	expr    string
+	runtime runtime_expression.Expression
+}
+
+func ParseExtraction(expr string) (Extraction, error) {
+	switch {
+	case expr == "status":
+		return Extraction{source: fromStatus}, nil
+	case expr == "body":
+		return Extraction{source: fromBody}, nil
+	case runtime_expression.IsExpression(expr):
+		runtime, err := runtime_expression.Parse(expr)
+		if err != nil {
+			return Extraction{}, err
+		}
+		return Extraction{source: fromRuntimeExpression, expr: expr, runtime: runtime}, nil
+	case runtime_expression.IsTemplate(expr):
+		return Extraction{source: fromTemplate, expr: expr}, nil
+	case strings.HasPrefix(expr, "header."):
+		name := strings.TrimPrefix(expr, "header.")
+		if name == "" {
+			return Extraction{}, fmt.Errorf("header extraction needs a header name, e.g. header.Location")
+		}
+		return Extraction{source: fromHeader, expr: name}, nil
+	case jsonpath.IsPath(expr):
+		if _, err := jsonpath.Query(nil, expr); err != nil {
+			return Extraction{}, err
+		}
+		return Extraction{source: fromJSONPath, expr: expr}, nil
+	case strings.HasPrefix(expr, "/"):
+		if _, err := jsonpointer.Parse(expr); err != nil {
+			return Extraction{}, err
+		}
+		return Extraction{source: fromJSONPointer, expr: expr}, nil
+	}
+
+	return Extraction{}, fmt.Errorf("cannot extract %q, use status, body, header.<name>, a JSONPath ($.id), a JSON Pointer (/id) or a runtime expression ($response.body#/id)", expr)
+}
+
+// Extract captures a value from the exchange. A JSONPath matching several
+// values captures them all as an array.
+func (e Extraction) Extract(exchange runtime_expression.Exchange) (any, error) {
+	resp := exchange.Response
+
+	switch e.source {
+	case fromRuntimeExpression:
+		return e.runtime.Evaluate(exchange)
+	case fromTemplate:
+		return runtime_expression.EvaluateTemplate(e.expr, exchange)
+	case fromStatus:
+		return resp.StatusCode, nil
+	case fromBody:
+		return resp.Body, nil
+	case fromHeader:
+		values := resp.Headers.Values(e.expr)
+		if len(values) == 0 {
+			return nil, fmt.Errorf("response has no %s header", e.expr)
+		}
+		return values[0], nil
+	}
+
+	var body any
+	if err := json.Unmarshal([]byte(resp.Body), &body); err != nil {
+		return nil, fmt.Errorf("response body is not JSON: %w", err)
+	}
+
+	if e.source == fromJSONPointer {
+		return jsonpointer.Get(body, e.expr)
+	}
+
+	matches, err := jsonpath.Query(body, e.expr)
+	if err != nil {
+		return nil, err
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("JSONPath %q matched nothing in the response body", e.expr)
+	case 1:
+		return matches[0], nil
+	}
+	return matches, nil
+}
+
+// Extract parses expr and captures its value from the exchange.
+func Extract(expr string, exchange runtime_expression.Exchange) (any, error) {
+	extraction, err := ParseExtraction(expr)
+	if err != nil {
+		return nil, err
+	}
+	return extraction.Extract(exchange)
+}
@@ -0,0 +1,302 @@
+package chain
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/jsonpointer"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/planner"
+	"github.com/alexplayer15/parmesan/runtime_expression"
+)
+
+// linkEdge is a link from a response of one operation to another operation.
+type linkEdge struct {
+	name string
+	from operations.Operation
+	to   operations.Operation
+	link oas_struct.Link
+}
+
+// WorkflowFromLinks derives a workflow from the links declared on responses.
+// Only operations taking part in a link are included. Producers run before the
+// operations their links feed, and among operations that are ready at the same
+// time creates run first and deletes last.
+func WorkflowFromLinks(oas oas_struct.OAS) (Workflow, error) {
+	edges, err := findLinkEdges(oas)
+	if err != nil {
+		return Workflow{}, err
+	}
+	if len(edges) == 0 {
+		return Workflow{}, fmt.Errorf("the OAS declares no links between operations")
+	}
+
+	ordered, err := orderByLinks(edges)
+	if err != nil {
+		return Workflow{}, err
+	}
+
+	workflow := Workflow{Name: fmt.Sprintf("%s (links)", oas.Info.Title)}
+	stepIndex := map[string]int{}
+	for _, op := range ordered {
+		stepIndex[op.Key()] = len(workflow.Steps)
+		workflow.Steps = append(workflow.Steps, stepForOperation(op))
+	}
+
+	usedNames := map[string]bool{}
+	for _, edge := range edges {
+		producer := &workflow.Steps[stepIndex[edge.from.Key()]]
+		consumer := &workflow.Steps[stepIndex[edge.to.Key()]]
+
+		for _, param := range slices.Sorted(maps.Keys(edge.link.Parameters)) {
+			in, name := parameterLocation(edge.to, param)
+			if stepHasParameter(*consumer, in, name) {
+				continue
+			}
+			value := linkValue(producer, edge.name+"."+name, edge.link.Parameters[param], usedNames)
+			setStepParameter(consumer, in, name, value)
+		}
+
+		body, ok := edge.link.RequestBody.(map[string]any)
+		if edge.link.RequestBody != nil && !ok {
+			log.Printf("[WARNING] link %s: only a requestBody of fields to values can be chained, ignoring it", edge.name)
+			continue
+		}
+		for _, field := range slices.Sorted(maps.Keys(body)) {
+			if consumer.Body == nil {
+				consumer.Body = map[string]any{}
+			}
+			if _, set := consumer.Body[field]; !set {
+				consumer.Body[field] = linkValue(producer, edge.name+".body."+field, body[field], usedNames)
+			}
+		}
+	}
+
+	return workflow, nil
+}
+
+// findLinkEdges collects every link in the spec in a stable order.
+func findLinkEdges(oas oas_struct.OAS) ([]linkEdge, error) {
+	var edges []linkEdge
+
+	for _, op := range operations.ListOperations(oas) {
+		for _, status := range slices.Sorted(maps.Keys(op.Details.Responses)) {
+			response, err := oas.ResolveResponse(op.Details.Responses[status])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op.Name(), err)
+			}
+
+			for _, name := range slices.Sorted(maps.Keys(response.Links)) {
+				link, err := oas.ResolveLink(response.Links[name])
+				if err != nil {
+					return nil, fmt.Errorf("%s link %s: %w", op.Name(), name, err)
+				}
+
+				target, err := linkTarget(oas, link)
+				if err != nil {
+					return nil, fmt.Errorf("%s link %s: %w", op.Name(), name, err)
+				}
+
+				//an operation cannot run before itself, so self links cannot be chained
+				if target.Key() == op.Key() {
+					continue
+				}
+
+				edges = append(edges, linkEdge{name: name, from: op, to: target, link: link})
+			}
+		}
+	}
+
+	return edges, nil
+}
+
+// linkTarget finds the operation a link points at, by operationId or by an
+// operationRef such as #/paths/~1users~1{id}/get.
+func linkTarget(oas oas_struct.OAS, link oas_struct.Link) (operations.Operation, error) {
+	if link.OperationId != "" {
+		op, ok := operations.FindByOperationId(oas, link.OperationId)
+		if !ok {
+			return operations.Operation{}, fmt.Errorf("no operation with operationId %q in the OAS", link.OperationId)
+		}
+		return op, nil
+	}
+
+	pointer, ok := strings.CutPrefix(link.OperationRef, "#")
+	if !ok {
+		return operations.Operation{}, fmt.Errorf("only operationRefs within the same OAS are supported, got %q", link.OperationRef)
+	}
+	tokens, err := jsonpointer.Parse(pointer)
+	if err != nil || len(tokens) != 3 || tokens[0] != "paths" {
+		return operations.Operation{}, fmt.Errorf("operationRef %q does not point at an operation", link.OperationRef)
+	}
+
+	for _, op := range operations.ListOperations(oas) {
+		if op.Path == tokens[1] && op.Method == strings.ToUpper(tokens[2]) {
+			return op, nil
+		}
+	}
+	return operations.Operation{}, fmt.Errorf("operationRef %q does not point at an operation in the OAS", link.OperationRef)
+}
+
+// orderByLinks sorts the linked operations so producers come before consumers.
+func orderByLinks(edges []linkEdge) ([]operations.Operation, error) {
+	nodes := map[string]operations.Operation{}
+	incoming := map[string]int{}
+	outgoing := map[string][]string{}
+
+	for _, edge := range edges {
+		from, to := edge.from.Key(), edge.to.Key()
+		nodes[from], nodes[to] = edge.from, edge.to
+		if slices.Contains(outgoing[from], to) {
+			continue
+		}
+		outgoing[from] = append(outgoing[from], to)
+		incoming[to]++
+	}
+
+	var ready []operations.Operation
+	for key, op := range nodes {
+		if incoming[key] == 0 {
+			ready = append(ready, op)
+		}
+	}
+
+	var ordered []operations.Operation
+	for len(ready) > 0 {
+		sortOperations(ready)
+		next := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, next)
+
+		for _, to := range outgoing[next.Key()] {
+			incoming[to]--
+			if incoming[to] == 0 {
+				ready = append(ready, nodes[to])
+			}
+		}
+	}
+
+	if len(ordered) != len(nodes) {
+		var cyclic []string
+		for key, op := range nodes {
+			if incoming[key] > 0 {
+				cyclic = append(cyclic, op.Name())
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("links form a cycle between %s", strings.Join(cyclic, ", "))
+	}
+
+	return ordered, nil
+}
+
+// sortOperations puts creates first and deletes last when links leave a choice.
+func sortOperations(ops []operations.Operation) {
+	sort.SliceStable(ops, func(i, j int) bool {
+		if planner.CRUDPhase(ops[i].Method) != planner.CRUDPhase(ops[j].Method) {
+			return planner.CRUDPhase(ops[i].Method) < planner.CRUDPhase(ops[j].Method)
+		}
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		return ops[i].Method < ops[j].Method
+	})
+}
+
+func stepForOperation(op operations.Operation) Step {
+	if op.Details.OperationId != "" {
+		return Step{Name: op.Name(), OperationId: op.Details.OperationId}
+	}
+	return Step{Name: op.Name(), Path: op.Path, Method: op.Method}
+}
+
+// parameterLocation splits a link parameter name, which may be qualified with
+// its location as in path.id, and otherwise looks the location up on the
+// target operation.
+func parameterLocation(op operations.Operation, param string) (string, string) {
+	if in, name, ok := strings.Cut(param, "."); ok && slices.Contains([]string{"path", "query", "header", "cookie"}, in) {
+		return in, name
+	}
+
+	for _, declared := range op.Details.Parameters {
+		if declared.Name == param {
+			return declared.In, param
+		}
+	}
+
+	if strings.Contains(op.Path, "{"+param+"}") {
+		return "path", param
+	}
+	return "query", param
+}
+
+// linkValue turns a link value into what the consumer step sends. Runtime
+// expressions become extractions on the producer step and a {{placeholder}}
+// on the consumer, anything else is sent as it is.
+func linkValue(producer *Step, variable string, value any, usedNames map[string]bool) any {
+	expr, ok := value.(string)
+	if !ok || !(runtime_expression.IsExpression(expr) || runtime_expression.IsTemplate(expr)) {
+		return value
+	}
+
+	name := variable
+	for i := 2; usedNames[name]; i++ {
+		name = fmt.Sprintf("%s.%d", variable, i)
+	}
+	usedNames[name] = true
+
+	if producer.Extract == nil {
+		producer.Extract = map[string]string{}
+	}
+	producer.Extract[name] = expr
+
+	return fmt.Sprintf("{{%s}}", name)
+}
+
+// stepHasParameter reports whether an earlier link already set the parameter.
+func stepHasParameter(step Step, in string, name string) bool {
+	var set bool
+	switch in {
+	case "path":
+		_, set = step.PathParams[name]
+	case "header":
+		_, set = step.Headers[name]
+	case "cookie":
+		set = strings.Contains("; "+step.Headers["Cookie"], "; "+name+"=")
+	default:
+		_, set = step.Query[name]
+	}
+	return set
+}
+
+func setStepParameter(step *Step, in string, name string, value any) {
+	text := runtime_expression.Stringify(value)
+
+	var target *map[string]string
+	switch in {
+	case "path":
+		target = &step.PathParams
+	case "header", "cookie":
+		target = &step.Headers
+	default:
+		target = &step.Query
+	}
+	if *target == nil {
+		*target = map[string]string{}
+	}
+
+	if in == "cookie" {
+		cookie := fmt.Sprintf("%s=%s", name, text)
+		if existing := step.Headers["Cookie"]; existing != "" {
+			cookie = existing + "; " + cookie
+		}
+		name, text = "Cookie", cookie
+	}
+
+	(*target)[name] = text
+}
@@ -0,0 +1,37 @@
+package chain
+
+import (
+	"maps"
+
+	"github.com/alexplayer15/parmesan/runtime_expression"
+	"github.com/alexplayer15/parmesan/variables"
+)
+
+// Variables holds workflow variables and the values extracted so far. Values
+// keep their JSON type so a captured number is sent as a number.
+type Variables map[string]any
+
+func NewVariables(initial map[string]any) Variables {
+	vars := Variables{}
+	maps.Copy(vars, initial)
+	return vars
+}
+
+// Lookup exposes the variables as {{var}} placeholders. Strings are used as
+// they are, everything else as JSON.
+func (v Variables) Lookup() variables.Lookup {
+	return func(name string) (string, bool) {
+		value, ok := v[name]
+		if !ok {
+			return "", false
+		}
+		return runtime_expression.Stringify(value), true
+	}
+}
+
+// Resolve substitutes placeholders in a body value. A string that is a single
+// placeholder for a non-string variable becomes that value, so
+// `count: "{{total}}"` sends a number when total was captured as one.
+func (v Variables) Resolve(value any, lookup variables.Lookup) any {
+	return variables.ResolveValue(value, v, lookup)
+}
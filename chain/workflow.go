@@ -0,0 +1,179 @@
+package chain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/script"
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow is a chain of requests where values captured from earlier responses
+// feed into later requests.
+//
+//	name: user lifecycle
+//	variables:
+//	  tenant: acme
+//	steps:
+//	  - name: create user
+//	    operationId: createUser
+//	    headers:
+//	      X-Tenant: "{{tenant}}"
+//	    body:
+//	      name: Alex
+//	    extract:
+//	      userId: $.id
+//	  - name: get user
+//	    path: /users/{id}
+//	    method: GET
+//	    pathParams:
+//	      id: "{{userId}}"
+//	    responseScript: |
+//	      if json.decode(response["body"])["id"] != var("userId"):
+//	          fail("fetched the wrong user")
+type Workflow struct {
+	Name              string         `yaml:"name"`
+	Variables         map[string]any `yaml:"variables"`
+	ContinueOnFailure bool           `yaml:"continueOnFailure"`
+	Steps             []Step         `yaml:"steps"`
+}
+
+// Step is one request in a workflow. The operation is chosen by operationId or
+// by path and method. Values may use {{var}} placeholders for workflow
+// variables, earlier extractions and the selected environment. Script is
+// Starlark run on the built request and ResponseScript on its response after
+// the extractions, either inline or as the path of a .star file relative to the
+// workflow file.
+type Step struct {
+	Name              string            `yaml:"name"`
+	OperationId       string            `yaml:"operationId"`
+	Path              string            `yaml:"path"`
+	Method            string            `yaml:"method"`
+	PathParams        map[string]string `yaml:"pathParams"`
+	Query             map[string]string `yaml:"query"`
+	Headers           map[string]string `yaml:"headers"`
+	Body              map[string]any    `yaml:"body"`
+	Extract           map[string]string `yaml:"extract"`
+	Script            string            `yaml:"script"`
+	ResponseScript    string            `yaml:"responseScript"`
+	ContinueOnFailure *bool             `yaml:"continueOnFailure"`
+
+	//baseDir is the directory of the workflow file, which scripts are read relative to
+	baseDir string
+}
+
+func LoadWorkflow(workflowFile string) (Workflow, error) {
+	content, err := os.ReadFile(workflowFile)
+	if err != nil {
+		return Workflow{}, fmt.Errorf("failed to read workflow file %s", workflowFile)
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(workflowFile), ".")
+	if ext != "yml" && ext != "yaml" {
+		return Workflow{}, fmt.Errorf("workflow file must be YAML, you entered a %s file", ext)
+	}
+
+	var workflow Workflow
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return Workflow{}, fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	if len(workflow.Steps) == 0 {
+		return Workflow{}, fmt.Errorf("workflow %s has no steps", workflowFile)
+	}
+
+	for i := range workflow.Steps {
+		workflow.Steps[i].baseDir = filepath.Dir(workflowFile)
+	}
+
+	return workflow, nil
+}
+
+// Validate checks that every step refers to exactly one operation in the spec,
+// so a typo is reported before anything is sent.
+func (w Workflow) Validate(oas oas_struct.OAS) error {
+	for i, step := range w.Steps {
+		if _, err := step.Operation(oas); err != nil {
+			return fmt.Errorf("step %d (%s): %w", i+1, step.Label(i), err)
+		}
+		for name, expr := range step.Extract {
+			if _, err := ParseExtraction(expr); err != nil {
+				return fmt.Errorf("step %d (%s): extract %s: %w", i+1, step.Label(i), name, err)
+			}
+		}
+		if _, err := step.CompiledScript(); err != nil {
+			return fmt.Errorf("step %d (%s): %w", i+1, step.Label(i), err)
+		}
+		if _, err := step.CompiledResponseScript(); err != nil {
+			return fmt.Errorf("step %d (%s): response %w", i+1, step.Label(i), err)
+		}
+	}
+	return nil
+}
+
+// Label names the step in output, falling back to its position and operation.
+func (s Step) Label(index int) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if s.OperationId != "" {
+		return s.OperationId
+	}
+	return fmt.Sprintf("step %d: %s %s", index+1, strings.ToUpper(s.Method), s.Path)
+}
+
+// ContinuesOnFailure reports whether later steps run after this one fails. A
+// step's own setting overrides the workflow's.
+func (s Step) ContinuesOnFailure(w Workflow) bool {
+	if s.ContinueOnFailure != nil {
+		return *s.ContinueOnFailure
+	}
+	return w.ContinueOnFailure
+}
+
+// Operation resolves the operation the step sends.
+func (s Step) Operation(oas oas_struct.OAS) (operations.Operation, error) {
+	if s.OperationId != "" {
+		if s.Path != "" || s.Method != "" {
+			return operations.Operation{}, fmt.Errorf("use either operationId or path and method, not both")
+		}
+		op, ok := operations.FindByOperationId(oas, s.OperationId)
+		if !ok {
+			return operations.Operation{}, fmt.Errorf("no operation with operationId %q in the OAS", s.OperationId)
+		}
+		return op, nil
+	}
+
+	if s.Path == "" || s.Method == "" {
+		return operations.Operation{}, fmt.Errorf("a step needs an operationId or a path and method")
+	}
+
+	for _, op := range operations.ListOperations(oas) {
+		if op.Path == s.Path && op.Method == strings.ToUpper(s.Method) {
+			return op, nil
+		}
+	}
+
+	return operations.Operation{}, fmt.Errorf("no %s %s operation in the OAS", strings.ToUpper(s.Method), s.Path)
+}
+
+// CompiledScript compiles the step's script, or returns nil when it has none.
+func (s Step) CompiledScript() (*script.Script, error) {
+	if s.Script == "" {
+		return nil, nil
+	}
+	return script.Load(s.Script, s.baseDir, "step script")
+}
+
+// CompiledResponseScript compiles the step's response script, or returns nil
+// when it has none.
+func (s Step) CompiledResponseScript() (*script.Script, error) {
+	if s.ResponseScript == "" {
+		return nil, nil
+	}
+	return script.Load(s.ResponseScript, s.baseDir, "response script")
+}
@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/alexplayer15/parmesan/chain"
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/runtime_expression"
+	"github.com/alexplayer15/parmesan/script"
+	"github.com/alexplayer15/parmesan/variables"
+	"github.com/spf13/cobra"
+)
+
+func newChainRequestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "chain-request <oas-file> [workflow-file]",
+		Short: "Send a workflow of requests, feeding values from each response into the next",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			useLinks, _ := cmd.Flags().GetBool("links")
+			if useLinks == (len(args) == 2) {
+				return fmt.Errorf("pass either a workflow file or --links to derive the workflow from the OAS links")
+			}
+
+			oasFile := args[0]
+			for _, file := range args {
+				if err := checkIfFileExists(file); err != nil {
+					return err
+				}
+			}
+
+			oas, err := parseOASFile(oasFile)
+			if err != nil {
+				return fmt.Errorf("error reading OAS file: %w", err)
+			}
+			if err := checkIfOASFileIsValid(oas); err != nil {
+				return fmt.Errorf("invalid OAS structure: %w", err)
+			}
+
+			chosenServerIndex, _ := cmd.Flags().GetInt("with-server")
+			if err := validateChosenServerUrl(chosenServerIndex, oas); err != nil {
+				return err
+			}
+
+			workflowFile := oasFile
+			var workflow chain.Workflow
+			if useLinks {
+				workflow, err = chain.WorkflowFromLinks(oas)
+				if err != nil {
+					return fmt.Errorf("failed to derive a workflow from links: %w", err)
+				}
+				printDerivedWorkflow(cmd, workflow)
+			} else {
+				workflowFile = args[1]
+				workflow, err = chain.LoadWorkflow(workflowFile)
+				if err != nil {
+					return err
+				}
+			}
+			if err := workflow.Validate(oas); err != nil {
+				return err
+			}
+
+			spec := sendSpec{oas: oas, serverURL: oas.Servers[chosenServerIndex].URL}
+
+			//results are named after the workflow file, or the OAS when chaining by links
+			return runWorkflow(cmd, workflow, spec, chosenServerIndex, workflowFile)
+		},
+	}
+
+	addRunFlags(cmd)
+	cmd.Flags().Bool("links", false, "Derive the workflow from the links declared on the OAS responses instead of a workflow file.")
+
+	return cmd
+}
+
+func printDerivedWorkflow(cmd *cobra.Command, workflow chain.Workflow) {
+	fmt.Fprintf(cmd.OutOrStdout(), "Chaining %d operation(s) from OAS links:\n", len(workflow.Steps))
+	for i, step := range workflow.Steps {
+		fmt.Fprintf(cmd.OutOrStdout(), "  %d. %s\n", i+1, step.Label(i))
+	}
+}
+
+// runWorkflow sends the steps in order. A step fails when it breaks the run
+// criteria, a value cannot be extracted from its response or its response
+// script fails, and the remaining steps are skipped unless it continues on
+// failure.
+func runWorkflow(cmd *cobra.Command, workflow chain.Workflow, spec sendSpec, chosenServerIndex int, workflowFile string) error {
+	r, err := newRun(cmd, spec, workflowFile)
+	if err != nil {
+		return err
+	}
+
+	if err := r.start(); err != nil {
+		return err
+	}
+	defer r.writer.Close()
+
+	ctx, stop := notifyOnInterrupt(cmd.Context())
+	defer stop()
+
+	vars := chain.NewVariables(workflow.Variables)
+	lookup := variables.Chain(vars.Lookup(), r.lookup)
+	env := script.Env{Lookup: lookup, Set: func(variable string, value any) { vars[variable] = value }, Output: cmd.ErrOrStderr()}
+
+	stopped := false
+	notBuilt := 0
+
+	for i, step := range workflow.Steps {
+		name := step.Label(i)
+
+		if stopped {
+			r.summary.Skipped++
+			continue
+		}
+		if ctx.Err() != nil {
+			r.summary.AddNotSent(name)
+			continue
+		}
+
+		failed := false
+
+		req, err := chain.BuildRequest(spec.oas, chosenServerIndex, step, vars, lookup)
+		if err == nil {
+			req, err = runStepScript(step, req, env)
+		}
+		if err != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "Step %q could not be built: %v\n", name, err)
+			notBuilt++
+			r.summary.Skipped++
+			failed = true
+		} else {
+			result, sent := r.exchange(ctx, req)
+			if !sent {
+				continue
+			}
+
+			if result.Err == nil {
+				//the workflow was validated, so the step's operation exists
+				op, _ := step.Operation(spec.oas)
+				exchange := runtime_expression.Exchange{
+					Request:    result.Request,
+					Response:   result.Response,
+					PathParams: op.PathParams(spec.serverURL, req.Url),
+				}
+				for _, variable := range slices.Sorted(maps.Keys(step.Extract)) {
+					value, err := chain.Extract(step.Extract[variable], exchange)
+					if err != nil {
+						result.Failures = append(result.Failures, fmt.Sprintf("extract %s: %v", variable, err))
+						continue
+					}
+					vars[variable] = value
+				}
+
+				//the workflow was validated, so the script compiles
+				if responseScript, _ := step.CompiledResponseScript(); responseScript != nil {
+					if err := responseScript.AfterResponse(result.Request, result.Response, env); err != nil {
+						result.Failures = append(result.Failures, err.Error())
+					}
+				}
+			}
+
+			if err := r.record(result); err != nil {
+				return err
+			}
+			failed = !result.Passed()
+		}
+
+		if failed && !step.ContinuesOnFailure(workflow) {
+			stopped = true
+		}
+	}
+
+	if err := r.finish(ctx); err != nil {
+		return err
+	}
+
+	if notBuilt > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%w: %d step(s) could not be built", errors.ErrRequestsFailed, notBuilt)
+	}
+
+	return nil
+}
+
+func runStepScript(step chain.Step, req request_sender.Request, env script.Env) (request_sender.Request, error) {
+	//the workflow was validated, so the script compiles
+	requestScript, _ := step.CompiledScript()
+	if requestScript == nil {
+		return req, nil
+	}
+	return requestScript.BeforeRequest(req, env)
+}
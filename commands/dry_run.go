@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/script"
+	"github.com/alexplayer15/parmesan/variables"
+	"github.com/spf13/cobra"
+)
+
+// dryRunRequests resolves every request exactly as send-request would, then prints
+// them in .http format instead of opening any connections. Nothing is sent, so
+// the plan has no created ids to fill in and variables scripts set are not kept.
+func dryRunRequests(cmd *cobra.Command, requests []request_sender.Request, method string, paths []string, hooksFile hooks_logic.HooksFile, lookup variables.Lookup, spec sendSpec, outputDir string, oasFile string) error {
+	var builder strings.Builder
+	resolved := 0
+
+	for _, req := range requests {
+		if spec.plan != nil {
+			req = spec.plan.Fill(req)
+		}
+
+		if !requestMatchesFilters(req, method, paths) {
+			continue
+		}
+
+		req, err := resolveRequestVariables(req, lookup)
+		if err != nil {
+			return err
+		}
+
+		req, matchingHooks, err := applyHooks(req, hooksFile, spec, nil, lookup)
+		if err != nil {
+			return err
+		}
+
+		//hooks can use variables too
+		req, err = resolveRequestVariables(req, lookup)
+		if err != nil {
+			return err
+		}
+
+		req, err = runRequestScripts(req, matchingHooks, script.Env{Lookup: lookup, Output: cmd.ErrOrStderr()})
+		if err != nil {
+			return err
+		}
+
+		writeDryRunRequest(&builder, requestName(spec.oas, spec.serverURL, req), req, matchingHooks)
+		resolved++
+	}
+
+	//only write to a file when the user chose where it should go
+	if !cmd.Flags().Changed("output") {
+		_, err := io.WriteString(cmd.OutOrStdout(), builder.String())
+		return err
+	}
+
+	if err := ensureDirectory(outputDir); err != nil {
+		return err
+	}
+
+	filePath := filepath.Join(outputDir, changeExtension(oasFile, ".dry-run.http"))
+	if err := os.WriteFile(filePath, []byte(builder.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write dry run file: %w", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Saved %d resolved request(s) to %s\n", resolved, filePath)
+
+	return nil
+}
+
+func writeDryRunRequest(builder *strings.Builder, name string, req request_sender.Request, matchingHooks []hooks_logic.HookEntry) {
+	var described []string
+	for _, hook := range matchingHooks {
+		described = append(described, hook.Describe())
+	}
+
+	hookDescription := "no hook"
+	if len(described) == 1 {
+		hookDescription = "hook: " + described[0]
+	} else if len(described) > 1 {
+		hookDescription = "hooks: " + strings.Join(described, ", ")
+	}
+
+	fmt.Fprintf(builder, "#### %s (%s)\n", name, hookDescription)
+	fmt.Fprintf(builder, "%s %s\n", req.Method, req.Url)
+
+	headerNames := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+
+	for _, name := range headerNames {
+		fmt.Fprintf(builder, "%s: %s\n", name, req.Headers[name])
+	}
+
+	builder.WriteString("\n")
+	builder.WriteString(req.Body)
+	builder.WriteString("\n\n")
+}
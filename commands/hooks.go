@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/alexplayer15/parmesan/errors"
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/spf13/cobra"
+)
+
+func newHooksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Work with hooks files",
+	}
+
+	cmd.AddCommand(newHooksLintCmd())
+	cmd.AddCommand(newHooksSchemaCmd())
+
+	return cmd
+}
+
+func newHooksLintCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lint <oas-file> <hooks-file-or-directory>",
+		Short: "Check hooks against an OpenAPI Spec without sending any requests",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oasFile, hooksFile := args[0], args[1]
+			if err := checkIfFileExists(oasFile); err != nil {
+				return err
+			}
+
+			oas, err := parseOASFile(oasFile)
+			if err != nil {
+				return fmt.Errorf("error reading OAS file: %w", err)
+			}
+			if err := checkIfOASFileIsValid(oas); err != nil {
+				return fmt.Errorf("invalid OAS structure: %w", err)
+			}
+
+			issues, err := hooks_logic.LintHooksFile(oas, hooksFile)
+			if err != nil {
+				return err
+			}
+
+			for _, issue := range issues {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s:%s\n", issue.File, issue)
+			}
+
+			if len(issues) > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%w: %d problem(s) in %s", errors.ErrHooksLintFailed, len(issues), hooksFile)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: no problems found\n", hooksFile)
+			return nil
+		},
+	}
+}
+
+func newHooksSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the hooks format, for editors to check and complete hooks files",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := cmd.OutOrStdout().Write(hooks_logic.JSONSchema)
+			return err
+		},
+	}
+}
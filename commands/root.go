@@ -12,6 +12,9 @@ func NewRootCmd() *cobra.Command {
 
 	rootCmd.AddCommand(newGenerateRequestCmd())
 	rootCmd.AddCommand(newSendRequestCmd())
+	rootCmd.AddCommand(newSendFileCmd())
+	rootCmd.AddCommand(newChainRequestCmd())
+	rootCmd.AddCommand(newHooksCmd())
 
 	return rootCmd
 }
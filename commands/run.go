@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/alexplayer15/parmesan/chain"
+	"github.com/alexplayer15/parmesan/errors"
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/output"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/results"
+	"github.com/alexplayer15/parmesan/runtime_expression"
+	"github.com/alexplayer15/parmesan/script"
+	"github.com/alexplayer15/parmesan/variables"
+	"github.com/spf13/cobra"
+)
+
+// run is what every request sent by send-request, send-file and chain-request
+// shares: the HTTP client, pacing, failure criteria and where results go.
+type run struct {
+	cmd        *cobra.Command
+	spec       sendSpec
+	sourceFile string
+
+	client            *http.Client
+	limiter           *request_sender.RateLimiter
+	criteria          results.Criteria
+	validateResponses bool
+	redactHeaders     []string
+	reportTypes       []string
+	outputDir         string
+	outputFormats     []string
+	lookup            variables.Lookup
+
+	//captured holds the variables response hooks and scripts set, keeping their
+	//JSON type, which lookup also resolves
+	captured chain.Variables
+
+	writer     output.ResponseWriter
+	summary    *results.Summary
+	allResults []results.Result
+}
+
+// newRun reads the flags registered by addRunFlags. sourceFile names the output
+// files, so results from spec.yml are saved to spec.json.
+func newRun(cmd *cobra.Command, spec sendSpec, sourceFile string) (*run, error) {
+	r := &run{cmd: cmd, spec: spec, sourceFile: sourceFile, summary: results.NewSummary(), captured: chain.NewVariables(nil)}
+
+	transportOptions, err := getTransportOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	r.client, err = request_sender.NewHTTPClient(transportOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure HTTP client: %w", err)
+	}
+
+	r.limiter, err = getRateLimiter(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	r.criteria, err = getFailureCriteria(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	r.reportTypes, _ = cmd.Flags().GetStringSlice("report")
+	if err := validateReportTypes(r.reportTypes); err != nil {
+		return nil, err
+	}
+
+	//failing on schema violations only makes sense if responses are validated
+	validateResponses, _ := cmd.Flags().GetBool("validate-responses")
+	r.validateResponses = validateResponses || r.criteria.SchemaViolation
+	if r.validateResponses && !spec.loaded() {
+		return nil, fmt.Errorf("validating responses needs the OAS, pass it with --oas")
+	}
+
+	r.redactHeaders, _ = cmd.Flags().GetStringSlice("redact")
+
+	r.outputDir, _ = cmd.Flags().GetString("output")
+	r.outputFormats, _ = cmd.Flags().GetStringSlice("output-format")
+
+	if err := output.ValidateFormats(r.outputFormats); err != nil {
+		return nil, err
+	}
+	if err := validateOutputPath(r.outputDir); err != nil {
+		return nil, err
+	}
+
+	lookup, err := getVariableLookup(cmd, sourceFile)
+	if err != nil {
+		return nil, err
+	}
+	r.lookup = variables.Chain(r.captured.Lookup(), lookup)
+
+	return r, nil
+}
+
+// start creates the output directory and opens the output files. Nothing is
+// written before it is called, so a dry run can share the flags without leaving
+// files behind.
+func (r *run) start() error {
+	if err := ensureDirectory(r.outputDir); err != nil {
+		return err
+	}
+
+	writer, err := output.NewResponseWriter(r.outputFormats, r.outputDir, changeExtension(r.sourceFile, ""))
+	if err != nil {
+		return err
+	}
+	r.writer = writer
+	return nil
+}
+
+func (r *run) requestName(req request_sender.Request) string {
+	return requestName(r.spec.oas, r.spec.serverURL, req)
+}
+
+// exchange paces and sends req and evaluates the response without recording
+// it, so callers can add failures of their own first. It reports false when the
+// request was not sent because ctx was cancelled first or while it was in flight.
+func (r *run) exchange(ctx context.Context, req request_sender.Request) (results.Result, bool) {
+	if err := r.limiter.Wait(ctx, req.Url); err != nil {
+		r.summary.AddNotSent(r.requestName(req))
+		return results.Result{}, false
+	}
+
+	resp, err := request_sender.SendHTTPRequest(ctx, r.client, req)
+	if err != nil && ctx.Err() != nil {
+		//cancelled mid-flight, so there is no response worth recording
+		r.summary.AddNotSent(r.requestName(req))
+		return results.Result{}, false
+	}
+
+	result := results.Result{Request: req, Response: resp, Err: err}
+	if op, ok := operations.FindOperation(r.spec.oas, r.spec.serverURL, req.Method, req.Url); ok {
+		result.Operation = op.Name()
+		if err == nil && r.validateResponses {
+			result.Violations = validateAgainstOperation(r.spec.oas, op, resp)
+		}
+	} else if err == nil && r.validateResponses {
+		result.Violations = []string{fmt.Sprintf("no operation in the OAS matches %s %s", req.Method, req.Url)}
+	}
+	result.Failures = r.criteria.Evaluate(result)
+
+	return result, true
+}
+
+// scriptEnv lets scripts read the run's variables and set ones for later
+// requests, as captures do.
+func (r *run) scriptEnv() script.Env {
+	return script.Env{
+		Lookup: r.lookup,
+		Set: func(name string, value any) {
+			r.captured[name] = value
+		},
+		Output: r.cmd.ErrOrStderr(),
+	}
+}
+
+// checkResponse adds the outcome of the applied hooks' response assertions to
+// result and captures their values for later requests, then runs their response
+// scripts. A failed assertion, capture or script fails the request.
+func (r *run) checkResponse(result *results.Result, applied []hooks_logic.HookEntry) {
+	if result.Err != nil {
+		return
+	}
+
+	hook := hooks_logic.MergeHooks(applied).Response
+	if !hook.IsEmpty() {
+		failures := hook.Check(result.Response)
+		result.Assertions += hook.Assertions()
+		result.FailedAssertions += len(failures)
+		for _, failure := range failures {
+			result.Failures = append(result.Failures, "assertion failed: "+failure)
+		}
+
+		exchange := runtime_expression.Exchange{Request: result.Request, Response: result.Response}
+		for _, name := range slices.Sorted(maps.Keys(hook.Capture)) {
+			value, err := chain.Extract(hook.Capture[name], exchange)
+			if err != nil {
+				result.Failures = append(result.Failures, fmt.Sprintf("capture %s: %v", name, err))
+				continue
+			}
+			r.captured[name] = value
+			result.Captured = append(result.Captured, name)
+		}
+	}
+
+	//scripts run after the captures, so they can read them with var()
+	for _, entry := range applied {
+		responseScript, err := entry.ResponseScript()
+		if err == nil && responseScript != nil {
+			err = responseScript.AfterResponse(result.Request, result.Response, r.scriptEnv())
+		}
+		if err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("hook %s: %v", entry.Describe(), err))
+		}
+	}
+}
+
+// record adds result to the summary and saves it, with the error when the
+// request could not be sent. Only the saved copy is redacted, later steps of a
+// chain need the real values.
+func (r *run) record(result results.Result) error {
+	saved := result.Redacted(r.redactHeaders)
+
+	r.summary.Add(saved)
+	if len(r.reportTypes) > 0 {
+		r.allResults = append(r.allResults, saved)
+	}
+	printViolations(saved)
+
+	//requests that could not be sent are saved too, so the output matches the summary
+	if saved.Err != nil {
+		log.Printf("Failed to send request %s %s: %v", saved.Request.Method, saved.Request.Url, saved.Err)
+	}
+
+	return r.writer.Write(saved)
+}
+
+// finish flushes the output, writes reports, prints the summary and turns the
+// outcome into the command's exit status.
+func (r *run) finish(ctx context.Context) error {
+	if err := r.writer.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved all responses to %s\n", r.outputDir)
+
+	if err := writeReports(r.reportTypes, r.outputDir, r.sourceFile, r.allResults, r.summary.Skipped); err != nil {
+		return err
+	}
+
+	r.summary.Print(r.cmd.OutOrStdout())
+
+	if ctx.Err() != nil {
+		r.cmd.SilenceUsage = true
+		return fmt.Errorf("%w: %d request(s) not sent", errors.ErrRunInterrupted, r.summary.NotSent())
+	}
+
+	if r.summary.Failed > 0 {
+		//the run itself worked, so the usage text would only hide the summary
+		r.cmd.SilenceUsage = true
+		return fmt.Errorf("%w: %d of %d", errors.ErrRequestsFailed, r.summary.Failed, r.summary.Sent)
+	}
+
+	return nil
+}
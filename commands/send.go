@@ -1,26 +1,34 @@
 package commands
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"maps"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
 
+	"github.com/alexplayer15/parmesan/chain"
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/environment"
 	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/output"
+	"github.com/alexplayer15/parmesan/planner"
+	"github.com/alexplayer15/parmesan/reports"
 	"github.com/alexplayer15/parmesan/request_generator"
 	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/response_validator"
+	"github.com/alexplayer15/parmesan/results"
+	"github.com/alexplayer15/parmesan/script"
+	"github.com/alexplayer15/parmesan/variables"
 	"github.com/spf13/cobra"
 )
 
-type SavedResponse struct {
-	Method   string `json:"method"`
-	Url      string `json:"url"`
-	Status   int    `json:"status"`
-	Response any    `json:"response"`
-}
-
 func newSendRequestCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "send-request",
@@ -55,106 +63,437 @@ func newSendRequestCmd() *cobra.Command {
 				return err
 			}
 
-			method, _ := cmd.Flags().GetString("method")
-			paths, _ := cmd.Flags().GetStringSlice("path")
+			spec := sendSpec{oas: oas, serverURL: oas.Servers[chosenServerIndex].URL}
 
-			if err := request_sender.ValidateHTTPMethod(method); err != nil {
-				return err
-			}
-			for _, path := range paths {
-				if err := validatePathInput(path); err != nil {
-					return err //to do: use typed validation error in the above method and return it here
-				}
+			//creates go first and feed their ids to the operations that need them
+			if usePlan, _ := cmd.Flags().GetBool("plan"); usePlan {
+				spec.plan = planner.New(oas, spec.serverURL)
+				requests = spec.plan.Order(requests)
 			}
 
-			var allResponses []SavedResponse
-			hooks, _ := cmd.Flags().GetString("hooks")
+			return sendRequests(cmd, requests, spec, oasFile)
+		},
+	}
 
-			var hooksFile hooks_logic.HooksFile
+	addSendFlags(cmd)
+	cmd.Flags().Bool("plan", false, "Order requests so creates run first, then reads and updates, then deletes, filling path params with the ids the creates return. Without it requests are sent in OAS order.")
 
-			if hooks != "" {
-				hooksFile, err = hooks_logic.UnmarshalHooksFile(hooks)
-				if err != nil {
-					return err
-				}
-			}
+	return cmd
+}
 
-			for _, req := range requests {
-				if method != "*" && req.Method != method {
-					continue
-				}
-
-				if !urlMatchesPaths(req.Url, paths) {
-					continue
-				}
-
-				if hooks != "" {
-					matchingHook := hooks_logic.TryAndFindHookForThisRequest(hooksFile, req)
-
-					//to do: think of a way to reduce this nesting
-					if !matchingHook.IsEmpty() {
-						req.Body, err = hooks_logic.ModifyRequestBodyUsingHook(matchingHook, req.Body)
-						if err != nil {
-							return err
-						}
-					}
-				}
-
-				responseBody, statusCode, err := request_sender.SendHTTPRequest(req)
-				if err != nil {
-					log.Printf("Failed to send request %s %s: %v", req.Method, req.Url, err)
-					continue
-				}
-
-				var parsedBody any
-				if err := json.Unmarshal([]byte(responseBody), &parsedBody); err != nil {
-					log.Printf("Failed to parse JSON body for %s %s: %v. Saving as string.", req.Method, req.Url, err)
-					parsedBody = responseBody
-				}
-
-				savedResp := SavedResponse{
-					Method:   req.Method,
-					Url:      req.Url,
-					Status:   statusCode,
-					Response: parsedBody,
-				}
-
-				allResponses = append(allResponses, savedResp)
-			}
+// sendSpec is the OAS the requests were generated from. It is left empty when
+// sending a .http file without a spec, in which case operations cannot be looked up.
+// plan is set when send-request orders the requests by their dependencies.
+type sendSpec struct {
+	oas       oas_struct.OAS
+	serverURL string
+	plan      *planner.Plan
+}
 
-			outputDir, _ := cmd.Flags().GetString("output")
+func (s sendSpec) loaded() bool {
+	return s.oas.OpenAPI != ""
+}
 
-			if err := validateOutputPath(outputDir); err != nil {
-				return err
-			}
-			if err := ensureDirectory(outputDir); err != nil {
-				return err
-			}
+// sendRequests filters, hooks, sends, saves and reports on requests. sourceFile
+// names the output files, so results from spec.yml are saved to spec.json.
+func sendRequests(cmd *cobra.Command, requests []request_sender.Request, spec sendSpec, sourceFile string) error {
+	method, _ := cmd.Flags().GetString("method")
+	paths, _ := cmd.Flags().GetStringSlice("path")
+
+	if err := request_sender.ValidateHTTPMethod(method); err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := validatePathInput(path); err != nil {
+			return err //to do: use typed validation error in the above method and return it here
+		}
+	}
 
-			filePath := filepath.Join(outputDir, changeExtension(oasFile, ".json"))
+	r, err := newRun(cmd, spec, sourceFile)
+	if err != nil {
+		return err
+	}
 
-			jsonData, err := json.MarshalIndent(allResponses, "", "  ")
-			if err != nil {
-				return fmt.Errorf("failed to marshal all responses: %w", err)
-			}
+	hooks, _ := cmd.Flags().GetString("hooks")
 
-			if err := os.WriteFile(filePath, jsonData, 0644); err != nil {
-				return fmt.Errorf("failed to write final output file: %w", err)
-			}
+	var hooksFile hooks_logic.HooksFile
 
-			fmt.Printf("Saved all responses to %s\n", filePath)
+	if hooks != "" {
+		hooksFile, err = hooks_logic.UnmarshalHooksFile(hooks)
+		if err != nil {
+			return err
+		}
+		if err := validateCaptures(hooksFile); err != nil {
+			return fmt.Errorf("invalid hooks file %s: %w", hooks, err)
+		}
+	}
 
-			return nil
-		},
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		return dryRunRequests(cmd, requests, method, paths, hooksFile, r.lookup, spec, r.outputDir, sourceFile)
 	}
 
-	cmd.Flags().Int("with-server", 0, "Which server url to use from OAS. 0 = First URL.")
+	if err := r.start(); err != nil {
+		return err
+	}
+	defer r.writer.Close()
+
+	ctx, stop := notifyOnInterrupt(cmd.Context())
+	defer stop()
+
+	for _, req := range requests {
+		if spec.plan != nil {
+			req = spec.plan.Fill(req)
+		}
+
+		//filtered out requests are never sent, so their variables do not need values
+		if !requestMatchesFilters(req, method, paths) {
+			r.summary.Skipped++
+			continue
+		}
+
+		//once interrupted the rest are only counted, so their variables do not need values
+		if ctx.Err() != nil {
+			r.summary.AddNotSent(r.requestName(req))
+			continue
+		}
+
+		req, err = resolveRequestVariables(req, r.lookup)
+		if err != nil {
+			return err
+		}
+
+		req, applied, err := applyHooks(req, hooksFile, spec, r.captured, r.lookup)
+		if err != nil {
+			return err
+		}
+
+		//hooks can use variables too, including ones captured from earlier responses
+		req, err = resolveRequestVariables(req, r.lookup)
+		if err != nil {
+			return err
+		}
+
+		//scripts run last, so they see the request exactly as it will be sent
+		req, err = runRequestScripts(req, applied, r.scriptEnv())
+		if err != nil {
+			return err
+		}
+
+		result, sent := r.exchange(ctx, req)
+		if !sent {
+			continue
+		}
+		r.checkResponse(&result, applied)
+		if err := r.record(result); err != nil {
+			return err
+		}
+		if spec.plan != nil && result.Err == nil {
+			spec.plan.Record(req, result.Response)
+		}
+	}
+
+	return r.finish(ctx)
+}
+
+// addSendFlags registers the flags of the commands that send a list of requests.
+func addSendFlags(cmd *cobra.Command) {
 	cmd.Flags().String("method", "*", "Choose with requests you want to send from your OAS by method. Default is all methods.")
 	cmd.Flags().StringSlice("path", []string{}, "Choose with requests you want to send from your OAS by path. Default is all paths.")
+	cmd.Flags().String("hooks", "", "Location of a YAML or JSON hooks file, or a directory of them, to modify request values.")
+	cmd.Flags().Bool("dry-run", false, "Print the final requests, after filters and hooks, without sending them. Writes them to a .http file instead if --output is given.")
+
+	addRunFlags(cmd)
+}
+
+// addRunFlags registers the flags read by newRun.
+func addRunFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("with-server", 0, "Which server url to use from OAS. 0 = First URL.")
 	cmd.Flags().String("output", ".", "Directory of output for HTTP responses.")
-	cmd.Flags().String("hooks", "", "Location of hooks file to modify request values.")
+	cmd.Flags().StringSlice("output-format", []string{output.FormatJSON}, "How responses are saved: json, ndjson, har, per-operation. Each response is written as soon as it arrives.")
+	cmd.Flags().Bool("validate-responses", false, "Validate each response's status code, headers, Content-Type and body against the OAS.")
+	cmd.Flags().StringSlice("fail-on", []string{results.CriterionTransport, results.CriterionNonSuccess}, "What makes the run fail with a non-zero exit code: non-2xx, schema, transport, latency. Pass an empty value to never fail.")
+	cmd.Flags().Duration("max-latency", 0, "Responses slower than this fail the run, e.g. 500ms. Adds latency to the default --fail-on; an explicit --fail-on must list latency. 0 = no limit.")
+	cmd.Flags().StringSlice("report", []string{}, "Extra reports to write to the output directory: junit, html.")
+	cmd.Flags().StringSlice("redact", results.DefaultRedactedHeaders, "Headers whose values are replaced with [REDACTED] in saved output and reports. Pass an empty value to keep everything.")
+	cmd.Flags().String("ca-cert", "", "PEM bundle of extra CA certificates to trust, e.g. a private CA.")
+	cmd.Flags().String("cert", "", "Client certificate (PEM) for mutual TLS. Requires --key.")
+	cmd.Flags().String("key", "", "Client private key (PEM) for mutual TLS. Requires --cert.")
+	cmd.Flags().Bool("insecure", false, "Skip TLS certificate verification. Only use against test environments.")
+	cmd.Flags().String("tls-min-version", "1.2", "Minimum TLS version to negotiate: 1.0, 1.1, 1.2 or 1.3.")
+	cmd.Flags().String("sni", "", "Override the server name sent in the TLS handshake and used to verify the certificate.")
+	cmd.Flags().Float64("rate", 0, "Maximum requests per second. 0 = unlimited.")
+	cmd.Flags().Int("burst", 1, "Number of requests allowed to go out at once before --rate applies.")
+	cmd.Flags().Duration("delay", 0, "Fixed delay between requests, e.g. 250ms.")
+	cmd.Flags().Bool("rate-per-host", false, "Apply --rate, --burst and --delay to each host separately instead of globally.")
+	cmd.Flags().String("env", "", "Environment to load {{variables}} from, e.g. dev. Read from http-client.env.json, http-client.private.env.json and parmesan.env.yml next to the input file.")
+	cmd.Flags().StringSlice("env-file", []string{}, "Extra environment files (JSON or YAML) to load after the default ones.")
+	cmd.Flags().String("proxy", "", "Proxy URL for all requests. Defaults to HTTP_PROXY, HTTPS_PROXY and NO_PROXY from the environment.")
+}
 
-	return cmd
+func getTransportOptions(cmd *cobra.Command) (request_sender.TransportOptions, error) {
+	var opts request_sender.TransportOptions
+
+	opts.CACertFile, _ = cmd.Flags().GetString("ca-cert")
+	opts.ClientCertFile, _ = cmd.Flags().GetString("cert")
+	opts.ClientKeyFile, _ = cmd.Flags().GetString("key")
+	opts.Insecure, _ = cmd.Flags().GetBool("insecure")
+	opts.MinTLSVersion, _ = cmd.Flags().GetString("tls-min-version")
+	opts.ServerName, _ = cmd.Flags().GetString("sni")
+	opts.ProxyURL, _ = cmd.Flags().GetString("proxy")
+
+	for _, file := range []string{opts.CACertFile, opts.ClientCertFile, opts.ClientKeyFile} {
+		if file == "" {
+			continue
+		}
+		if err := checkIfFileExists(file); err != nil {
+			return request_sender.TransportOptions{}, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	return opts, nil
+}
+
+// getVariableLookup resolves {{var}} placeholders from the built-in dynamic
+// variables first and then the selected environment.
+func getVariableLookup(cmd *cobra.Command, sourceFile string) (variables.Lookup, error) {
+	envName, _ := cmd.Flags().GetString("env")
+	envFiles, _ := cmd.Flags().GetStringSlice("env-file")
+
+	if envName == "" && len(envFiles) > 0 {
+		return nil, fmt.Errorf("--env-file needs --env to choose an environment from it")
+	}
+	for _, file := range envFiles {
+		if err := checkIfFileExists(file); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	vars, err := environment.Load(filepath.Dir(sourceFile), envName, envFiles...)
+	if err != nil {
+		return nil, err
+	}
+
+	return variables.Chain(variables.Dynamic, variables.FromMap(vars)), nil
+}
+
+func resolveRequestVariables(req request_sender.Request, lookup variables.Lookup) (request_sender.Request, error) {
+	resolved, err := request_sender.ResolveVariables(req, lookup)
+	if err != nil {
+		return request_sender.Request{}, fmt.Errorf("failed to resolve variables in %s %s: %w", req.Method, req.Url, err)
+	}
+	return resolved, nil
+}
+
+func getRateLimiter(cmd *cobra.Command) (*request_sender.RateLimiter, error) {
+	var opts request_sender.RateLimitOptions
+
+	opts.RequestsPerSecond, _ = cmd.Flags().GetFloat64("rate")
+	opts.Burst, _ = cmd.Flags().GetInt("burst")
+	opts.Delay, _ = cmd.Flags().GetDuration("delay")
+	opts.PerHost, _ = cmd.Flags().GetBool("rate-per-host")
+
+	return request_sender.NewRateLimiter(opts)
+}
+
+func getFailureCriteria(cmd *cobra.Command) (results.Criteria, error) {
+	failOn, _ := cmd.Flags().GetStringSlice("fail-on")
+	maxLatency, _ := cmd.Flags().GetDuration("max-latency")
+
+	//a latency limit on its own fails slow requests on top of the default criteria
+	if maxLatency > 0 && !cmd.Flags().Changed("fail-on") {
+		failOn = append(failOn, results.CriterionLatency)
+	}
+
+	return results.ParseCriteria(failOn, maxLatency)
+}
+
+func validateReportTypes(reportTypes []string) error {
+	for _, reportType := range reportTypes {
+		if reportType != "junit" && reportType != "html" {
+			return fmt.Errorf("unknown report type %q, use junit or html", reportType)
+		}
+	}
+	return nil
+}
+
+func writeReports(reportTypes []string, outputDir string, oasFile string, runResults []results.Result, skipped int) error {
+	suiteName := changeExtension(oasFile, "")
+
+	for _, reportType := range reportTypes {
+		var reportPath string
+		var write func(io.Writer) error
+
+		switch reportType {
+		case "junit":
+			reportPath = filepath.Join(outputDir, changeExtension(oasFile, ".junit.xml"))
+			write = func(w io.Writer) error { return reports.WriteJUnit(w, suiteName, runResults, skipped) }
+		case "html":
+			reportPath = filepath.Join(outputDir, changeExtension(oasFile, ".html"))
+			write = func(w io.Writer) error { return reports.WriteHTML(w, suiteName, runResults, skipped) }
+		}
+
+		file, err := os.Create(reportPath)
+		if err != nil {
+			return fmt.Errorf("failed to create %s report: %w", reportType, err)
+		}
+		err = write(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Saved %s report to %s\n", reportType, reportPath)
+	}
+
+	return nil
+}
+
+// notifyOnInterrupt cancels the returned context on SIGINT or SIGTERM so the run
+// can stop and save what it has. Once cancelled the default handling is restored,
+// so pressing Ctrl-C a second time kills the process straight away.
+func notifyOnInterrupt(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return ctx, stop
+}
+
+func requestName(oas oas_struct.OAS, serverURL string, req request_sender.Request) string {
+	if op, ok := operations.FindOperation(oas, serverURL, req.Method, req.Url); ok {
+		return op.Name()
+	}
+	if req.Name != "" {
+		return req.Name
+	}
+	return fmt.Sprintf("%s %s", req.Method, req.Url)
+}
+
+func validateAgainstOperation(oas oas_struct.OAS, op operations.Operation, resp request_sender.Response) []string {
+	var violations []string
+	for _, violation := range response_validator.ValidateResponse(oas, op, resp) {
+		violations = append(violations, violation.String())
+	}
+	return violations
+}
+
+func printViolations(result results.Result) {
+	if len(result.Violations) == 0 {
+		return
+	}
+
+	fmt.Printf("%s (status %d): %d violation(s)\n", result.Name(), result.Response.StatusCode, len(result.Violations))
+	for _, violation := range result.Violations {
+		fmt.Printf("  - %s\n", violation)
+	}
+}
+
+func requestMatchesFilters(req request_sender.Request, method string, paths []string) bool {
+	if method != "*" && req.Method != method {
+		return false
+	}
+	return urlMatchesPaths(req.Url, paths)
+}
+
+// validateCaptures checks the captures of every response hook, which use the
+// same expressions as the extract section of a workflow.
+func validateCaptures(hooksFile hooks_logic.HooksFile) error {
+	for i, hook := range hooksFile {
+		if hook.Response == nil {
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(hook.Response.Capture)) {
+			if _, err := chain.ParseExtraction(hook.Response.Capture[name]); err != nil {
+				return fmt.Errorf("hook %d: capture %s: %w", i+1, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// runRequestScripts runs the script of each hook in the order the hooks were
+// applied.
+func runRequestScripts(req request_sender.Request, hooks []hooks_logic.HookEntry, env script.Env) (request_sender.Request, error) {
+	for _, hook := range hooks {
+		requestScript, err := hook.RequestScript()
+		if err != nil {
+			return req, fmt.Errorf("hook %s: %w", hook.Describe(), err)
+		}
+		if requestScript == nil {
+			continue
+		}
+		if req, err = requestScript.BeforeRequest(req, env); err != nil {
+			return req, fmt.Errorf("hook %s: %w", hook.Describe(), err)
+		}
+	}
+	return req, nil
+}
+
+// modifyBody applies a hook to a request body, checking the values it sets
+// against the operation's request body schema when there is one.
+func modifyBody(hook hooks_logic.HookEntry, body string, oas oas_struct.OAS, op operations.Operation) (string, error) {
+	if schema, ok := hooks_logic.RequestBodySchema(op); ok && op.Path != "" {
+		return hooks_logic.ModifyRequestBodyUsingSchema(hook, body, oas, schema)
+	}
+	return hooks_logic.ModifyRequestBodyUsingHook(hook, body)
+}
+
+// applyHooks modifies the request with every hook that matches it, merged from
+// the least to the most specific, and returns the hooks it applied. Variables
+// in the hooks are resolved first, keeping the type of those in typed, so when
+// the request's operation is known the values the hooks set are checked
+// against it as they will be sent.
+func applyHooks(req request_sender.Request, hooksFile hooks_logic.HooksFile, spec sendSpec, typed map[string]any, lookup variables.Lookup) (request_sender.Request, []hooks_logic.HookEntry, error) {
+	var op operations.Operation
+	if spec.loaded() {
+		op, _ = operations.FindOperation(spec.oas, spec.serverURL, req.Method, req.Url)
+	}
+
+	matchingHooks := hooks_logic.FindHooksForRequest(hooksFile, req, op, spec.serverURL)
+	for i, hook := range matchingHooks {
+		evaluated, err := hooks_logic.EvaluateHookFunctions(hook)
+		if err != nil {
+			return req, matchingHooks, err
+		}
+		matchingHooks[i] = evaluated
+	}
+
+	if len(matchingHooks) == 0 {
+		return req, nil, nil
+	}
+	merged, err := hooks_logic.ResolveHookVariables(hooks_logic.MergeHooks(matchingHooks), typed, lookup)
+	if err != nil {
+		return req, matchingHooks, err
+	}
+
+	if merged.ModifiesBody() {
+		body, err := modifyBody(merged, req.Body, spec.oas, op)
+		if err != nil {
+			return req, matchingHooks, err
+		}
+		req.Body = body
+	}
+
+	if !merged.ModifiesParameters() {
+		return req, matchingHooks, nil
+	}
+
+	if op.Path != "" {
+		if err := hooks_logic.ValidateHookParameters(merged, op, req); err != nil {
+			return req, matchingHooks, err
+		}
+	}
+
+	req, err = hooks_logic.ModifyRequestParametersUsingHook(merged, req, op.Path)
+	if err != nil {
+		return req, matchingHooks, err
+	}
+
+	return req, matchingHooks, nil
 }
 
 func urlMatchesPaths(url string, paths []string) bool {
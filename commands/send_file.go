@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/spf13/cobra"
+)
+
+func newSendFileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "send-file",
+		Short: "Send the requests in an existing .http or .rest file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			httpFile := args[0]
+			if err := checkIfFileExists(httpFile); err != nil {
+				return err
+			}
+			if err := checkHttpFileExtension(httpFile); err != nil {
+				return err
+			}
+
+			requests, err := request_sender.ParseHttpFile(httpFile)
+			if err != nil {
+				return err
+			}
+
+			spec, err := loadOptionalSpec(cmd)
+			if err != nil {
+				return err
+			}
+
+			return sendRequests(cmd, requests, spec, httpFile)
+		},
+	}
+
+	addSendFlags(cmd)
+	cmd.Flags().String("oas", "", "OAS the file was generated from. Needed for response validation and to name results by operation.")
+
+	return cmd
+}
+
+func checkHttpFileExtension(file string) error {
+	ext := strings.TrimPrefix(filepath.Ext(file), ".")
+	if ext != "http" && ext != "rest" {
+		return fmt.Errorf("unsupported file extension: %s, expected a .http or .rest file", ext)
+	}
+	return nil
+}
+
+func loadOptionalSpec(cmd *cobra.Command) (sendSpec, error) {
+	oasFile, _ := cmd.Flags().GetString("oas")
+	if oasFile == "" {
+		return sendSpec{}, nil
+	}
+
+	if err := checkIfFileExists(oasFile); err != nil {
+		return sendSpec{}, err
+	}
+	oas, err := parseOASFile(oasFile)
+	if err != nil {
+		return sendSpec{}, fmt.Errorf("error reading OAS file: %w", err)
+	}
+	if err := checkIfOASFileIsValid(oas); err != nil {
+		return sendSpec{}, fmt.Errorf("invalid OAS structure: %w", err)
+	}
+
+	chosenServerIndex, _ := cmd.Flags().GetInt("with-server")
+	if err := validateChosenServerUrl(chosenServerIndex, oas); err != nil {
+		return sendSpec{}, err
+	}
+
+	return sendSpec{oas: oas, serverURL: oas.Servers[chosenServerIndex].URL}, nil
+}
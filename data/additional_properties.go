@@ -0,0 +1,53 @@
+package oas_struct
+
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AdditionalProperties holds either form OAS allows: a boolean, or a schema
+// that every property not listed under properties must match.
+type AdditionalProperties struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+func (a *AdditionalProperties) UnmarshalYAML(value *yaml.Node) error {
+	var allowed bool
+	if err := value.Decode(&allowed); err == nil {
+		a.Allowed = allowed
+		return nil
+	}
+
+	var schema Schema
+	if err := value.Decode(&schema); err != nil {
+		return err
+	}
+	a.Allowed = true
+	a.Schema = &schema
+	return nil
+}
+
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		a.Allowed = allowed
+		return nil
+	}
+
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return err
+	}
+	a.Allowed = true
+	a.Schema = &schema
+	return nil
+}
+
+func (a AdditionalProperties) MarshalJSON() ([]byte, error) {
+	if a.Schema != nil {
+		return json.Marshal(a.Schema)
+	}
+	return json.Marshal(a.Allowed)
+}
@@ -1,29 +1,56 @@
 package oas_struct
 
 type Property struct {
-	Type        string              `json:"type" yaml:"type"`
-	Format      string              `json:"format,omitempty" yaml:"format,omitempty"`
-	Description string              `json:"description" yaml:"description"`
-	Example     any                 `json:"example" yaml:"example"`
-	Default     any                 `json:"default" yaml:"default"`
-	Items       *Schema             `json:"items,omitempty" yaml:"items,omitempty"`
-	Ref         string              `json:"$ref,omitempty" yaml:"$ref,omitempty"`
-	OneOf       []Schema            `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
-	AnyOf       []Schema            `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
-	AllOf       []Schema            `json:"allOf,omitempty" yaml:"allOf,omitempty"`
-	Properties  map[string]Property `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Type                 string                `json:"type" yaml:"type"`
+	Format               string                `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string                `json:"description" yaml:"description"`
+	Example              any                   `json:"example" yaml:"example"`
+	Default              any                   `json:"default" yaml:"default"`
+	Items                *Schema               `json:"items,omitempty" yaml:"items,omitempty"`
+	Ref                  string                `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	OneOf                []Schema              `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	AnyOf                []Schema              `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+	AllOf                []Schema              `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+	Properties           map[string]Property   `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string              `json:"required,omitempty" yaml:"required,omitempty"`
+	Enum                 []any                 `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Nullable             bool                  `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	AdditionalProperties *AdditionalProperties `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Minimum              *float64              `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *float64              `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	ExclusiveMinimum     any                   `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     any                   `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+	MinLength            *int                  `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength            *int                  `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Pattern              string                `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	MinItems             *int                  `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems             *int                  `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
 }
 
 type Schema struct {
-	Ref        string              `json:"$ref,omitempty" yaml:"$ref,omitempty"`
-	Type       string              `json:"type,omitempty" yaml:"type,omitempty"`
-	Properties map[string]Property `json:"properties,omitempty" yaml:"properties,omitempty"`
-	Example    any                 `json:"example" yaml:"example"`
-	Default    any                 `json:"default" yaml:"default"`
-	Items      *Schema             `json:"items,omitempty" yaml:"items,omitempty"`
-	OneOf      []Schema            `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
-	AnyOf      []Schema            `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
-	AllOf      []Schema            `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+	Ref                  string                `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string                `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string                `json:"format,omitempty" yaml:"format,omitempty"`
+	Properties           map[string]Property   `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Example              any                   `json:"example" yaml:"example"`
+	Default              any                   `json:"default" yaml:"default"`
+	Items                *Schema               `json:"items,omitempty" yaml:"items,omitempty"`
+	OneOf                []Schema              `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	AnyOf                []Schema              `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+	AllOf                []Schema              `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+	Required             []string              `json:"required,omitempty" yaml:"required,omitempty"`
+	Enum                 []any                 `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Nullable             bool                  `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	AdditionalProperties *AdditionalProperties `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Minimum              *float64              `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *float64              `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	ExclusiveMinimum     any                   `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     any                   `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+	MinLength            *int                  `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength            *int                  `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Pattern              string                `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	MinItems             *int                  `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems             *int                  `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
 }
 
 type Content struct {
@@ -43,11 +70,41 @@ type Parameter struct {
 	Example     string `json:"example" yaml:"example"`
 }
 
+type Header struct {
+	Description string `json:"description" yaml:"description"`
+	Required    bool   `json:"required" yaml:"required"`
+	Schema      Schema `json:"schema" yaml:"schema"`
+}
+
+// Link describes how values from a response feed the parameters or body of
+// another operation, named by operationId or by an operationRef such as
+// #/paths/~1users~1{id}/get. Values are usually runtime expressions like
+// $response.body#/id.
+type Link struct {
+	Ref          string         `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	OperationId  string         `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	OperationRef string         `json:"operationRef,omitempty" yaml:"operationRef,omitempty"`
+	Parameters   map[string]any `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody  any            `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Description  string         `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type Response struct {
+	Ref         string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Description string             `json:"description" yaml:"description"`
+	Headers     map[string]Header  `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Content     map[string]Content `json:"content,omitempty" yaml:"content,omitempty"`
+	Links       map[string]Link    `json:"links,omitempty" yaml:"links,omitempty"`
+}
+
 type Method struct {
-	Summary     string      `json:"summary" yaml:"summary"`
-	Description string      `json:"description" yaml:"description"`
-	Parameters  []Parameter `json:"parameters" yaml:"parameters"`
-	RequestBody RequestBody `json:"requestBody" yaml:"requestBody"`
+	OperationId string              `json:"operationId" yaml:"operationId"`
+	Tags        []string            `json:"tags" yaml:"tags"`
+	Summary     string              `json:"summary" yaml:"summary"`
+	Description string              `json:"description" yaml:"description"`
+	Parameters  []Parameter         `json:"parameters" yaml:"parameters"`
+	RequestBody RequestBody         `json:"requestBody" yaml:"requestBody"`
+	Responses   map[string]Response `json:"responses" yaml:"responses"`
 }
 
 type Server struct {
@@ -61,7 +118,9 @@ type Info struct {
 }
 
 type Components struct {
-	Schemas map[string]Schema `json:"schemas" yaml:"schemas"`
+	Schemas   map[string]Schema   `json:"schemas" yaml:"schemas"`
+	Responses map[string]Response `json:"responses" yaml:"responses"`
+	Links     map[string]Link     `json:"links" yaml:"links"`
 }
 
 type OAS struct {
@@ -0,0 +1,104 @@
+package oas_struct
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	schemaRefPrefix   = "#/components/schemas/"
+	responseRefPrefix = "#/components/responses/"
+	linkRefPrefix     = "#/components/links/"
+)
+
+func (oas OAS) ResolveRef(ref string) (Schema, error) {
+	if !strings.HasPrefix(ref, schemaRefPrefix) {
+		return Schema{}, fmt.Errorf("unsupported ref format: %s", ref)
+	}
+
+	name := strings.TrimPrefix(ref, schemaRefPrefix)
+	schema, ok := oas.Components.Schemas[name]
+	if !ok {
+		return Schema{}, fmt.Errorf("schema not found: %s", name)
+	}
+	return schema, nil
+}
+
+// ResolveSchema follows $ref until it reaches a schema defined inline.
+func (oas OAS) ResolveSchema(schema Schema) (Schema, error) {
+	seen := map[string]bool{}
+	for schema.Ref != "" {
+		if seen[schema.Ref] {
+			return Schema{}, fmt.Errorf("circular ref: %s", schema.Ref)
+		}
+		seen[schema.Ref] = true
+
+		resolved, err := oas.ResolveRef(schema.Ref)
+		if err != nil {
+			return Schema{}, err
+		}
+		schema = resolved
+	}
+	return schema, nil
+}
+
+func (oas OAS) ResolveResponse(response Response) (Response, error) {
+	if response.Ref == "" {
+		return response, nil
+	}
+	if !strings.HasPrefix(response.Ref, responseRefPrefix) {
+		return Response{}, fmt.Errorf("unsupported ref format: %s", response.Ref)
+	}
+
+	name := strings.TrimPrefix(response.Ref, responseRefPrefix)
+	resolved, ok := oas.Components.Responses[name]
+	if !ok {
+		return Response{}, fmt.Errorf("response not found: %s", name)
+	}
+	return resolved, nil
+}
+
+func (oas OAS) ResolveLink(link Link) (Link, error) {
+	if link.Ref == "" {
+		return link, nil
+	}
+	if !strings.HasPrefix(link.Ref, linkRefPrefix) {
+		return Link{}, fmt.Errorf("unsupported ref format: %s", link.Ref)
+	}
+
+	name := strings.TrimPrefix(link.Ref, linkRefPrefix)
+	resolved, ok := oas.Components.Links[name]
+	if !ok {
+		return Link{}, fmt.Errorf("link not found: %s", name)
+	}
+	return resolved, nil
+}
+
+// AsSchema converts a property into the equivalent schema so both can be walked the same way.
+func (p Property) AsSchema() Schema {
+	return Schema{
+		Ref:                  p.Ref,
+		Type:                 p.Type,
+		Format:               p.Format,
+		Properties:           p.Properties,
+		Example:              p.Example,
+		Default:              p.Default,
+		Items:                p.Items,
+		OneOf:                p.OneOf,
+		AnyOf:                p.AnyOf,
+		AllOf:                p.AllOf,
+		Required:             p.Required,
+		Enum:                 p.Enum,
+		Nullable:             p.Nullable,
+		AdditionalProperties: p.AdditionalProperties,
+		Minimum:              p.Minimum,
+		Maximum:              p.Maximum,
+		ExclusiveMinimum:     p.ExclusiveMinimum,
+		ExclusiveMaximum:     p.ExclusiveMaximum,
+		MinLength:            p.MinLength,
+		MaxLength:            p.MaxLength,
+		Pattern:              p.Pattern,
+		MinItems:             p.MinItems,
+		MaxItems:             p.MaxItems,
+	}
+}
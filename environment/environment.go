@@ -0,0 +1,124 @@
+package environment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment files are looked up next to the file being sent. Later files
+// override earlier ones, so private values win over the shared, committed ones.
+const (
+	YAMLEnvFile    = "parmesan.env.yml"
+	PublicEnvFile  = "http-client.env.json"
+	PrivateEnvFile = "http-client.private.env.json"
+)
+
+// SharedEnvironment holds variables that every environment in a file inherits,
+// as in the JetBrains HTTP Client.
+const SharedEnvironment = "$shared"
+
+// environmentsFile maps environment names to their variables.
+type environmentsFile map[string]map[string]any
+
+// Load returns the variables of the environment called name, merged from the
+// default environment files in dir and then extraFiles in order. $shared from
+// every file is merged first, so any file's named environment overrides it.
+// Missing default files are skipped. An empty name loads no environment.
+func Load(dir string, name string, extraFiles ...string) (map[string]string, error) {
+	vars := make(map[string]string)
+	if name == "" {
+		return vars, nil
+	}
+
+	var files []string
+	for _, defaultFile := range []string{YAMLEnvFile, strings.TrimSuffix(YAMLEnvFile, ".yml") + ".yaml", PublicEnvFile, PrivateEnvFile} {
+		path := filepath.Join(dir, defaultFile)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	files = append(files, extraFiles...)
+
+	var loaded []environmentsFile
+	for _, file := range files {
+		environments, err := readEnvironmentsFile(file)
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, environments)
+	}
+
+	//the named environment overrides $shared whichever files they are in
+	for _, environments := range loaded {
+		addVariables(vars, environments[SharedEnvironment])
+	}
+	found := false
+	for _, environments := range loaded {
+		if environment, ok := environments[name]; ok {
+			addVariables(vars, environment)
+			found = true
+		}
+	}
+
+	if !found {
+		if len(files) == 0 {
+			return nil, fmt.Errorf("environment %q not found: no environment files in %s", name, dir)
+		}
+		return nil, fmt.Errorf("environment %q not found in %s", name, strings.Join(files, ", "))
+	}
+
+	return vars, nil
+}
+
+func readEnvironmentsFile(file string) (environmentsFile, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read environment file %s", file)
+	}
+
+	var environments environmentsFile
+
+	switch ext := strings.TrimPrefix(filepath.Ext(file), "."); ext {
+	case "json":
+		//keep numbers as written, float64 would turn large ids into exponents
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		if err := decoder.Decode(&environments); err != nil {
+			return nil, fmt.Errorf("invalid JSON in environment file %s: %w", file, err)
+		}
+	case "yml", "yaml":
+		if err := yaml.Unmarshal(content, &environments); err != nil {
+			return nil, fmt.Errorf("invalid YAML in environment file %s: %w", file, err)
+		}
+	default:
+		return nil, fmt.Errorf("environment file must be JSON or YAML, you entered a %s file", ext)
+	}
+
+	return environments, nil
+}
+
+// addVariables stringifies values so numbers and booleans can be used in
+// placeholders, and keeps objects and arrays as JSON.
+func addVariables(vars map[string]string, environment map[string]any) {
+	for key, value := range environment {
+		switch v := value.(type) {
+		case string:
+			vars[key] = v
+		case nil:
+			vars[key] = ""
+		case map[string]any, []any:
+			encoded, err := json.Marshal(v)
+			if err == nil {
+				vars[key] = string(encoded)
+			}
+		default:
+			vars[key] = fmt.Sprint(v)
+		}
+	}
+}
@@ -11,10 +11,19 @@ type HookError struct {
 
 const (
 	ErrCodeHookBodyFieldDoesNotExist ErrorCode = "HookBodyFieldNotFound"
+	ErrCodeHookInvalidKey            ErrorCode = "HookInvalidKey"
+	ErrCodeHookIndexOutOfRange       ErrorCode = "HookIndexOutOfRange"
+	ErrCodeHookFieldNotAContainer    ErrorCode = "HookFieldNotAContainer"
+	ErrCodeHookParameterDoesNotExist ErrorCode = "HookParameterNotFound"
+	ErrCodeHookSelectorMatchedNone   ErrorCode = "HookSelectorMatchedNothing"
+	ErrCodeHookValueInvalid          ErrorCode = "HookValueDoesNotMatchSchema"
 )
 
 func (e *HookError) Error() string {
-	return fmt.Sprintf("hook error: %s %s: %s", e.HookField, e.HookValue, e.Msg)
+	if e.HookValue == nil {
+		return fmt.Sprintf("hook error: %s: %s", e.HookField, e.Msg)
+	}
+	return fmt.Sprintf("hook error: %s %v: %s", e.HookField, e.HookValue, e.Msg)
 }
 
 func NewHookError(hookField string, hookValue any, code ErrorCode, msg string) error {
@@ -29,3 +38,27 @@ func NewHookError(hookField string, hookValue any, code ErrorCode, msg string) e
 func NewMissingHookFieldError(hookField string) error {
 	return NewHookError(hookField, nil, ErrCodeHookBodyFieldDoesNotExist, fmt.Sprintf("the field %s in the hooks file does not exist in the request body you are trying to modify", hookField))
 }
+
+func NewInvalidHookKeyError(hookField string, msg string) error {
+	return NewHookError(hookField, nil, ErrCodeHookInvalidKey, msg)
+}
+
+func NewHookIndexOutOfRangeError(hookField string, length int) error {
+	return NewHookError(hookField, nil, ErrCodeHookIndexOutOfRange, fmt.Sprintf("the array has %d element(s)", length))
+}
+
+func NewHookFieldNotAContainerError(hookField string, expected string) error {
+	return NewHookError(hookField, nil, ErrCodeHookFieldNotAContainer, fmt.Sprintf("the field %s in the request body is not %s", hookField, expected))
+}
+
+func NewUnknownHookParameterError(hookField string, hookValue any, in string, operation string) error {
+	return NewHookError(hookField, hookValue, ErrCodeHookParameterDoesNotExist, fmt.Sprintf("%s does not declare a %s parameter with this name", operation, in))
+}
+
+func NewHookSelectorMatchedNothingError(hookField string) error {
+	return NewHookError(hookField, nil, ErrCodeHookSelectorMatchedNone, "the JSONPath matched nothing in the request body")
+}
+
+func NewInvalidHookValueError(hookField string, hookValue any, msg string) error {
+	return NewHookError(hookField, hookValue, ErrCodeHookValueInvalid, msg)
+}
@@ -0,0 +1,26 @@
+package errors
+
+import "fmt"
+
+// ParseError reports a problem in an .http file together with the line it
+// was found on. The underlying error is kept so callers can still match on
+// ValidationError codes with errors.As.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func NewParseError(line int, err error) error {
+	return &ParseError{
+		Line: line,
+		Err:  err,
+	}
+}
@@ -12,10 +12,16 @@ const (
 	ErrCodeInvalidPrefix ErrorCode = "InvalidPrefix"
 	ErrCodeURLParsing    ErrorCode = "URLParsing"
 	ErrCodeMissingHost   ErrorCode = "MissingHost"
+	ErrCodeInvalidTLS    ErrorCode = "InvalidTLSVersion"
+	ErrCodeInvalidProxy  ErrorCode = "InvalidProxyURL"
+	ErrCodeUnresolvedVar ErrorCode = "UnresolvedVariable"
 )
 
 var (
-	ErrEmptyHTTPFile = errors.New("HTTP file is empty")
+	ErrEmptyHTTPFile   = errors.New("HTTP file is empty")
+	ErrRequestsFailed  = errors.New("requests failed the run criteria")
+	ErrRunInterrupted  = errors.New("run interrupted")
+	ErrHooksLintFailed = errors.New("hooks file has problems")
 )
 
 type ValidationError struct {
@@ -53,3 +59,15 @@ func NewURLParsingError(param, value string) error {
 func NewMissingHostError(param, value string) error {
 	return NewValidationError(param, value, ErrCodeMissingHost, "host is missing from URL")
 }
+
+func NewInvalidTLSVersionError(param, value string) error {
+	return NewValidationError(param, value, ErrCodeInvalidTLS, fmt.Sprintf("%s is not a supported TLS version, use 1.0, 1.1, 1.2 or 1.3", value))
+}
+
+func NewInvalidProxyURLError(param, value string) error {
+	return NewValidationError(param, value, ErrCodeInvalidProxy, "proxy must be an absolute URL such as http://proxy:3128")
+}
+
+func NewUnresolvedVariableError(param, value string) error {
+	return NewValidationError(param, value, ErrCodeUnresolvedVar, fmt.Sprintf("{{%s}} is not defined in the file, the environment or the built-in variables", value))
+}
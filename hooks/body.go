@@ -0,0 +1,318 @@
+package hooks_logic
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/jsonpath"
+	"github.com/alexplayer15/parmesan/jsonpointer"
+)
+
+// keyToken is one step of a hook key: a field name, an array index, or the
+// end of an array to append to.
+type keyToken struct {
+	name     string
+	index    int
+	isIndex  bool
+	isAppend bool
+}
+
+// resolveHookKey turns a hook key into the locations it addresses in body. A
+// key is a JSON Pointer when it starts with /, a JSONPath when it starts with $,
+// and otherwise dot separated. Only a JSONPath can address several locations.
+func resolveHookKey(body any, key string) ([][]keyToken, error) {
+	switch {
+	case strings.HasPrefix(key, "/"):
+		tokens, err := pointerToTokens(body, key)
+		if err != nil {
+			return nil, err
+		}
+		return [][]keyToken{tokens}, nil
+
+	case jsonpath.IsPath(key):
+		pointers, err := jsonpath.QueryPointers(body, key)
+		if err != nil {
+			return nil, errors.NewInvalidHookKeyError(key, err.Error())
+		}
+		if len(pointers) == 0 {
+			return nil, errors.NewHookSelectorMatchedNothingError(key)
+		}
+
+		var locations [][]keyToken
+		for _, pointer := range pointers {
+			tokens, err := pointerToTokens(body, pointer)
+			if err != nil {
+				return nil, err
+			}
+			locations = append(locations, tokens)
+		}
+		return locations, nil
+	}
+
+	tokens, err := parseHookKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return [][]keyToken{tokens}, nil
+}
+
+// pointerToTokens reads a JSON Pointer against body, as its tokens are indices
+// in arrays and field names everywhere else. - appends to an array.
+func pointerToTokens(body any, pointer string) ([]keyToken, error) {
+	references, err := jsonpointer.Parse(pointer)
+	if err != nil {
+		return nil, errors.NewInvalidHookKeyError(pointer, err.Error())
+	}
+
+	var tokens []keyToken
+	node := body
+	for i, reference := range references {
+		array, isArray := node.([]any)
+		if !isArray {
+			tokens = append(tokens, keyToken{name: reference})
+			object, _ := node.(map[string]any)
+			node = object[reference]
+			continue
+		}
+
+		if reference == "-" && i == len(references)-1 {
+			tokens = append(tokens, keyToken{isAppend: true})
+			break
+		}
+		if _, err := strconv.Atoi(reference); err != nil {
+			return nil, errors.NewInvalidHookKeyError(pointer, fmt.Sprintf("%q is not an array index", reference))
+		}
+		index, err := jsonpointer.ArrayIndex(reference, len(array))
+		if err != nil {
+			return nil, errors.NewHookIndexOutOfRangeError(jsonpointer.Format(references[:i+1]), len(array))
+		}
+		tokens = append(tokens, keyToken{index: index, isIndex: true})
+		node = array[index]
+	}
+
+	return tokens, nil
+}
+
+// parseHookKey splits a key such as items[2].sku into its fields and indices.
+// A key starting with an index, like [0].sku, addresses a body that is an array.
+func parseHookKey(key string) ([]keyToken, error) {
+	var tokens []keyToken
+
+	for i, part := range strings.Split(key, ".") {
+		name, rest, hasIndex := strings.Cut(part, "[")
+		if name == "" && !(i == 0 && hasIndex) {
+			return nil, errors.NewInvalidHookKeyError(key, "keys cannot have empty parts")
+		}
+		if name != "" {
+			tokens = append(tokens, keyToken{name: name})
+		}
+
+		for hasIndex {
+			indexText, after, closed := strings.Cut(rest, "]")
+			index, err := strconv.Atoi(indexText)
+			if !closed || err != nil || index < 0 {
+				return nil, errors.NewInvalidHookKeyError(key, "array indices must be written as [n] with n 0 or more")
+			}
+			tokens = append(tokens, keyToken{index: index, isIndex: true})
+
+			if after != "" && !strings.HasPrefix(after, "[") {
+				return nil, errors.NewInvalidHookKeyError(key, "expected . or [ after an array index")
+			}
+			rest, hasIndex = strings.CutPrefix(after, "[")
+		}
+	}
+
+	return tokens, nil
+}
+
+func formatKey(tokens []keyToken) string {
+	var key strings.Builder
+	for i, token := range tokens {
+		if token.isAppend {
+			key.WriteString("[-]")
+			continue
+		}
+		if token.isIndex {
+			fmt.Fprintf(&key, "[%d]", token.index)
+			continue
+		}
+		if i > 0 {
+			key.WriteString(".")
+		}
+		key.WriteString(token.name)
+	}
+	return key.String()
+}
+
+// valueCheck decides whether newVal may be set at path. existing is the value
+// it replaces, if the field exists.
+type valueCheck func(path []keyToken, existing any, exists bool, newVal any) error
+
+// setField sets the value at tokens within node and returns the updated node.
+// Fields that do not exist yet are added, along with any objects leading to
+// them. A field name reached through an array is set on every element.
+func setField(node any, tokens []keyToken, newVal any, pathSoFar []keyToken, check valueCheck) (any, error) {
+	if len(tokens) == 0 {
+		if err := check(pathSoFar, node, true, newVal); err != nil {
+			return nil, err
+		}
+		return newVal, nil
+	}
+
+	token := tokens[0]
+	path := append(slices.Clone(pathSoFar), token)
+
+	if token.isAppend {
+		array, ok := node.([]any)
+		if !ok {
+			return nil, errors.NewHookFieldNotAContainerError(formatKey(pathSoFar), "an array")
+		}
+		if err := check(path, nil, false, newVal); err != nil {
+			return nil, err
+		}
+		return append(array, newVal), nil
+	}
+
+	if token.isIndex {
+		array, ok := node.([]any)
+		if !ok {
+			return nil, errors.NewHookFieldNotAContainerError(formatKey(pathSoFar), "an array")
+		}
+		if token.index >= len(array) {
+			return nil, errors.NewHookIndexOutOfRangeError(formatKey(path), len(array))
+		}
+		updated, err := setField(array[token.index], tokens[1:], newVal, path, check)
+		if err != nil {
+			return nil, err
+		}
+		array[token.index] = updated
+		return array, nil
+	}
+
+	switch typed := node.(type) {
+	case map[string]any:
+		existing, exists := typed[token.name]
+		if !exists {
+			added, err := newField(tokens[1:], newVal, path, check)
+			if err != nil {
+				return nil, err
+			}
+			typed[token.name] = added
+			return typed, nil
+		}
+		updated, err := setField(existing, tokens[1:], newVal, path, check)
+		if err != nil {
+			return nil, err
+		}
+		typed[token.name] = updated
+		return typed, nil
+
+	case []any:
+		for i, item := range typed {
+			if _, ok := item.(map[string]any); !ok {
+				continue
+			}
+			updated, err := setField(item, tokens, newVal, append(slices.Clone(pathSoFar), keyToken{index: i, isIndex: true}), check)
+			if err != nil {
+				return nil, err
+			}
+			typed[i] = updated
+		}
+		return typed, nil
+
+	default:
+		return nil, errors.NewHookFieldNotAContainerError(formatKey(pathSoFar), "an object")
+	}
+}
+
+// newField builds the value for a field that is not in the body yet. Missing
+// objects on the way are created, but arrays cannot be made up from an index.
+func newField(tokens []keyToken, newVal any, pathSoFar []keyToken, check valueCheck) (any, error) {
+	if len(tokens) == 0 {
+		if err := check(pathSoFar, nil, false, newVal); err != nil {
+			return nil, err
+		}
+		return newVal, nil
+	}
+	if tokens[0].isIndex {
+		return nil, errors.NewMissingHookFieldError(formatKey(pathSoFar))
+	}
+
+	value, err := newField(tokens[1:], newVal, append(slices.Clone(pathSoFar), tokens[0]), check)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{tokens[0].name: value}, nil
+}
+
+// removeField deletes the field or array element at tokens within node and
+// returns the updated node.
+func removeField(node any, tokens []keyToken, pathSoFar []keyToken) (any, error) {
+	if len(tokens) == 0 {
+		return nil, errors.NewInvalidHookKeyError("$", "the whole body cannot be removed")
+	}
+
+	token := tokens[0]
+	path := append(slices.Clone(pathSoFar), token)
+	last := len(tokens) == 1
+
+	if token.isAppend {
+		return nil, errors.NewInvalidHookKeyError(formatKey(path), "- can only be used to add to an array")
+	}
+
+	if token.isIndex {
+		array, ok := node.([]any)
+		if !ok {
+			return nil, errors.NewHookFieldNotAContainerError(formatKey(pathSoFar), "an array")
+		}
+		if token.index >= len(array) {
+			return nil, errors.NewHookIndexOutOfRangeError(formatKey(path), len(array))
+		}
+		if last {
+			return slices.Delete(array, token.index, token.index+1), nil
+		}
+		updated, err := removeField(array[token.index], tokens[1:], path)
+		if err != nil {
+			return nil, err
+		}
+		array[token.index] = updated
+		return array, nil
+	}
+
+	switch typed := node.(type) {
+	case map[string]any:
+		existing, exists := typed[token.name]
+		if !exists {
+			return nil, errors.NewMissingHookFieldError(formatKey(path))
+		}
+		if last {
+			delete(typed, token.name)
+			return typed, nil
+		}
+		updated, err := removeField(existing, tokens[1:], path)
+		if err != nil {
+			return nil, err
+		}
+		typed[token.name] = updated
+		return typed, nil
+
+	case []any:
+		for i, item := range typed {
+			if _, ok := item.(map[string]any); !ok {
+				continue
+			}
+			updated, err := removeField(item, tokens, append(slices.Clone(pathSoFar), keyToken{index: i, isIndex: true}))
+			if err != nil {
+				return nil, err
+			}
+			typed[i] = updated
+		}
+		return typed, nil
+
+	default:
+		return nil, errors.NewHookFieldNotAContainerError(formatKey(pathSoFar), "an object")
+	}
+}
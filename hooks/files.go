@@ -0,0 +1,158 @@
+package hooks_logic
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// hookFileExtensions are the formats hooks can be written in. JSON is read by
+// the YAML parser, so both formats use the same keys.
+var hookFileExtensions = []string{".yml", ".yaml", ".json"}
+
+// JSONSchema describes the hooks format, so editors can check and complete
+// hooks files.
+//
+//go:embed hooks.schema.json
+var JSONSchema []byte
+
+// UnmarshalHooksFile reads hooks from a YAML or JSON file, or from every hooks
+// file in a directory and its subdirectories in name order. An entry of the
+// form include: path is replaced by the hooks of that file or directory,
+// relative to the file including it.
+func UnmarshalHooksFile(hooks string) (HooksFile, error) {
+	return loadHooks(hooks, nil)
+}
+
+// loadHooks loads the hooks at path. including is the chain of files whose
+// includes led here, to catch a file that ends up including itself.
+func loadHooks(path string, including []string) (HooksFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return HooksFile{}, fmt.Errorf("failed to read hooks file %s", path)
+	}
+
+	if info.IsDir() {
+		files, err := hookFilesIn(path)
+		if err != nil {
+			return HooksFile{}, err
+		}
+
+		var hooksFile HooksFile
+		for _, file := range files {
+			fileHooks, err := loadHooks(file, including)
+			if err != nil {
+				return HooksFile{}, err
+			}
+			hooksFile = append(hooksFile, fileHooks...)
+		}
+		return hooksFile, nil
+	}
+
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return HooksFile{}, fmt.Errorf("failed to read hooks file %s: %w", path, err)
+	}
+	if slices.Contains(including, absolute) {
+		return HooksFile{}, fmt.Errorf("hooks file %s includes itself: %s", path, strings.Join(append(including, absolute), " -> "))
+	}
+
+	entries, err := parseHooksFile(path)
+	if err != nil {
+		return HooksFile{}, err
+	}
+
+	var hooksFile HooksFile
+	for i, entry := range entries {
+		if entry.Include == "" {
+			hooksFile = append(hooksFile, entry)
+			continue
+		}
+
+		others := entry
+		others.Include = ""
+		if !others.IsEmpty() {
+			return HooksFile{}, fmt.Errorf("invalid hooks file %s: hook %d: include cannot be combined with other keys", path, i+1)
+		}
+
+		included, err := loadHooks(resolveInclude(path, entry.Include), append(slices.Clone(including), absolute))
+		if err != nil {
+			return HooksFile{}, fmt.Errorf("%s: hook %d: %w", path, i+1, err)
+		}
+		hooksFile = append(hooksFile, included...)
+	}
+
+	return hooksFile, nil
+}
+
+// parseHooksFile reads the entries of a single hooks file, without expanding
+// its includes.
+func parseHooksFile(path string) (HooksFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return HooksFile{}, fmt.Errorf("failed to read hooks file %s", path)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if !slices.Contains(hookFileExtensions, ext) {
+		return HooksFile{}, fmt.Errorf("hooks file must be YAML or JSON, you entered a %s file", strings.TrimPrefix(ext, "."))
+	}
+
+	if ext == ".json" {
+		var parsed any
+		if err := json.Unmarshal(content, &parsed); err != nil {
+			return HooksFile{}, fmt.Errorf("invalid JSON in %s: %w", path, err)
+		}
+	}
+
+	var hooksFile HooksFile
+	if err := yaml.Unmarshal(content, &hooksFile); err != nil {
+		return HooksFile{}, fmt.Errorf("invalid YAML in %s: %w", path, err)
+	}
+	for i := range hooksFile {
+		hooksFile[i].baseDir = filepath.Dir(path)
+	}
+
+	if err := hooksFile.Validate(); err != nil {
+		return HooksFile{}, fmt.Errorf("invalid hooks file %s: %w", path, err)
+	}
+
+	return hooksFile, nil
+}
+
+// hookFilesIn lists the hooks files in a directory and its subdirectories, in
+// name order.
+func hookFilesIn(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && slices.Contains(hookFileExtensions, strings.ToLower(filepath.Ext(path))) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks directory %s: %w", dir, err)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("hooks directory %s has no .yml, .yaml or .json files", dir)
+	}
+	return files, nil
+}
+
+func resolveInclude(includingFile string, include string) string {
+	if filepath.IsAbs(include) {
+		return include
+	}
+	return filepath.Join(filepath.Dir(includingFile), include)
+}
@@ -0,0 +1,375 @@
+package hooks_logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexplayer15/parmesan/runtime_expression"
+	"github.com/alexplayer15/parmesan/variables"
+)
+
+// functionCall is a parsed ${name(args)} expression.
+type functionCall struct {
+	name string
+	args []any
+}
+
+var fakeFirstNames = []string{"Alex", "Sam", "Jordan", "Taylor", "Morgan", "Casey", "Riley", "Jamie", "Robin", "Charlie"}
+var fakeLastNames = []string{"Smith", "Jones", "Taylor", "Brown", "Williams", "Wilson", "Johnson", "Davies", "Patel", "Wright"}
+var fakeCities = []string{"London", "Leeds", "Manchester", "Bristol", "Glasgow", "Cardiff", "Dublin", "Paris", "Berlin", "Madrid"}
+var fakeWords = []string{"parmesan", "cheddar", "brie", "gouda", "feta", "stilton", "edam", "halloumi", "ricotta", "mozzarella"}
+
+// EvaluateHookFunctions returns a copy of the hook with every ${function()}
+// in its values replaced by a freshly evaluated result:
+//
+//	${uuid()}                 random UUID v4
+//	${now()}                  current UTC time in RFC 3339
+//	${now("2006-01-02")}      current UTC time in a Go time layout
+//	${randomInt(1,100)}       random integer from 1 to 100
+//	${env("API_TENANT")}      environment variable, ${env("X", "fallback")} if unset
+//	${file("./p.json")}       file relative to the hooks file, parsed if it is JSON
+//	${faker.email()}          fake data: email, name, firstName, lastName, username, phone, city, word
+//
+// A value that is only a function keeps the function's type, so randomInt sets
+// a number and file can set a whole object. Write $${ for a literal ${.
+func EvaluateHookFunctions(hook HookEntry) (HookEntry, error) {
+	evaluated := hook
+	var err error
+
+	if hook.Body != nil {
+		evaluated.Body = make(map[string]any, len(hook.Body))
+		for key, value := range hook.Body {
+			if evaluated.Body[key], err = evaluateValue(value, hook.baseDir); err != nil {
+				return hook, fmt.Errorf("hook %s, body field %s: %w", hook.Describe(), key, err)
+			}
+		}
+	}
+
+	sections := []struct {
+		name   string
+		values *map[string]string
+	}{
+		{"headers", &evaluated.Headers},
+		{"query", &evaluated.Query},
+		{"pathParams", &evaluated.PathParams},
+		{"cookies", &evaluated.Cookies},
+	}
+	for _, section := range sections {
+		if *section.values == nil {
+			continue
+		}
+		values := maps.Clone(*section.values)
+		for name, value := range values {
+			if values[name], err = evaluateText(value, hook.baseDir); err != nil {
+				return hook, fmt.Errorf("hook %s, %s.%s: %w", hook.Describe(), section.name, name, err)
+			}
+		}
+		*section.values = values
+	}
+
+	return evaluated, nil
+}
+
+func evaluateValue(value any, baseDir string) (any, error) {
+	switch typed := value.(type) {
+	case string:
+		return evaluateString(typed, baseDir)
+	case map[string]any:
+		evaluated := make(map[string]any, len(typed))
+		for key, item := range typed {
+			var err error
+			if evaluated[key], err = evaluateValue(item, baseDir); err != nil {
+				return nil, err
+			}
+		}
+		return evaluated, nil
+	case []any:
+		evaluated := make([]any, len(typed))
+		for i, item := range typed {
+			var err error
+			if evaluated[i], err = evaluateValue(item, baseDir); err != nil {
+				return nil, err
+			}
+		}
+		return evaluated, nil
+	}
+	return value, nil
+}
+
+// evaluateText evaluates the functions in a value that must stay a string.
+func evaluateText(text string, baseDir string) (string, error) {
+	value, err := evaluateString(text, baseDir)
+	if err != nil {
+		return "", err
+	}
+	return runtime_expression.Stringify(value), nil
+}
+
+func evaluateString(text string, baseDir string) (any, error) {
+	if !strings.Contains(text, "${") {
+		return text, nil
+	}
+
+	var result strings.Builder
+	for rest := text; rest != ""; {
+		start := strings.Index(rest, "${")
+		if start == -1 {
+			result.WriteString(rest)
+			break
+		}
+
+		if start > 0 && rest[start-1] == '$' {
+			result.WriteString(rest[:start-1] + "${")
+			rest = rest[start+2:]
+			continue
+		}
+
+		call, length, err := parseFunctionCall(rest[start+2:])
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", text, err)
+		}
+		value, err := call.evaluate(baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s(): %w", call.name, err)
+		}
+
+		//a value that is only a function keeps the function's type
+		if start == 0 && start+2+length == len(text) {
+			return value, nil
+		}
+
+		result.WriteString(rest[:start])
+		result.WriteString(runtime_expression.Stringify(value))
+		rest = rest[start+2+length:]
+	}
+
+	return result.String(), nil
+}
+
+// parseFunctionCall parses name(args) } from the text after a ${ and returns
+// the call and how much of text it used, including the closing brace.
+func parseFunctionCall(text string) (functionCall, int, error) {
+	pos := skipSpaces(text, 0)
+
+	nameStart := pos
+	for pos < len(text) && (isNameChar(text[pos]) || text[pos] == '.') {
+		pos++
+	}
+	call := functionCall{name: text[nameStart:pos]}
+	if call.name == "" {
+		return functionCall{}, 0, fmt.Errorf("expected a function name after ${")
+	}
+
+	pos = skipSpaces(text, pos)
+	if pos >= len(text) || text[pos] != '(' {
+		return functionCall{}, 0, fmt.Errorf("expected ( after %s", call.name)
+	}
+	pos = skipSpaces(text, pos+1)
+
+	for pos < len(text) && text[pos] != ')' {
+		arg, next, err := parseArgument(text, pos)
+		if err != nil {
+			return functionCall{}, 0, err
+		}
+		call.args = append(call.args, arg)
+
+		pos = skipSpaces(text, next)
+		if pos < len(text) && text[pos] == ',' {
+			pos = skipSpaces(text, pos+1)
+		} else if pos < len(text) && text[pos] != ')' {
+			return functionCall{}, 0, fmt.Errorf("expected , or ) in the arguments of %s", call.name)
+		}
+	}
+	if pos >= len(text) {
+		return functionCall{}, 0, fmt.Errorf("missing ) after the arguments of %s", call.name)
+	}
+
+	pos = skipSpaces(text, pos+1)
+	if pos >= len(text) || text[pos] != '}' {
+		return functionCall{}, 0, fmt.Errorf("missing } after %s()", call.name)
+	}
+
+	return call, pos + 1, nil
+}
+
+// parseArgument parses a quoted string or a number starting at pos.
+func parseArgument(text string, pos int) (any, int, error) {
+	if quote := text[pos]; quote == '"' || quote == '\'' {
+		var value strings.Builder
+		for i := pos + 1; i < len(text); i++ {
+			switch {
+			case text[i] == '\\' && i+1 < len(text):
+				i++
+				value.WriteByte(text[i])
+			case text[i] == quote:
+				return value.String(), i + 1, nil
+			default:
+				value.WriteByte(text[i])
+			}
+		}
+		return nil, 0, fmt.Errorf("unterminated string argument")
+	}
+
+	end := pos
+	for end < len(text) && strings.IndexByte("+-.0123456789", text[end]) != -1 {
+		end++
+	}
+	number, err := strconv.ParseFloat(text[pos:end], 64)
+	if end == pos || err != nil {
+		return nil, 0, fmt.Errorf("arguments must be quoted strings or numbers")
+	}
+	return number, end, nil
+}
+
+func skipSpaces(text string, pos int) int {
+	for pos < len(text) && text[pos] == ' ' {
+		pos++
+	}
+	return pos
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func (c functionCall) evaluate(baseDir string) (any, error) {
+	if field, ok := strings.CutPrefix(c.name, "faker."); ok {
+		if err := c.expectArgs(0, 0); err != nil {
+			return nil, err
+		}
+		return fake(field)
+	}
+
+	switch c.name {
+	case "uuid":
+		if err := c.expectArgs(0, 0); err != nil {
+			return nil, err
+		}
+		return variables.NewUUID(), nil
+
+	case "now":
+		if err := c.expectArgs(0, 1); err != nil {
+			return nil, err
+		}
+		layout := time.RFC3339
+		if len(c.args) == 1 {
+			var err error
+			if layout, err = c.stringArg(0); err != nil {
+				return nil, err
+			}
+		}
+		return time.Now().UTC().Format(layout), nil
+
+	case "randomInt":
+		if err := c.expectArgs(2, 2); err != nil {
+			return nil, err
+		}
+		minimum, minOk := c.args[0].(float64)
+		maximum, maxOk := c.args[1].(float64)
+		if !minOk || !maxOk {
+			return nil, fmt.Errorf("takes two numbers, e.g. randomInt(1, 100)")
+		}
+		return variables.RandomInt(int64(minimum), int64(maximum)+1)
+
+	case "env":
+		if err := c.expectArgs(1, 2); err != nil {
+			return nil, err
+		}
+		name, err := c.stringArg(0)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			return value, nil
+		}
+		if len(c.args) == 2 {
+			return c.stringArg(1)
+		}
+		return nil, fmt.Errorf("environment variable %s is not set", name)
+
+	case "file":
+		if err := c.expectArgs(1, 1); err != nil {
+			return nil, err
+		}
+		path, err := c.stringArg(0)
+		if err != nil {
+			return nil, err
+		}
+		return readHookFile(path, baseDir)
+	}
+
+	return nil, fmt.Errorf("unknown function, use uuid, now, randomInt, env, file or faker.<field>")
+}
+
+func (c functionCall) expectArgs(minimum int, maximum int) error {
+	if len(c.args) < minimum || len(c.args) > maximum {
+		if minimum == maximum {
+			return fmt.Errorf("takes %d argument(s), got %d", minimum, len(c.args))
+		}
+		return fmt.Errorf("takes %d to %d arguments, got %d", minimum, maximum, len(c.args))
+	}
+	return nil
+}
+
+func (c functionCall) stringArg(i int) (string, error) {
+	text, ok := c.args[i].(string)
+	if !ok {
+		return "", fmt.Errorf("argument %d must be a quoted string", i+1)
+	}
+	return text, nil
+}
+
+// readHookFile reads a file relative to the hooks file. JSON files are parsed
+// so they can set objects and arrays.
+func readHookFile(path string, baseDir string) (any, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var value any
+		if err := json.Unmarshal(content, &value); err != nil {
+			return nil, fmt.Errorf("%s is not valid JSON: %w", path, err)
+		}
+		return value, nil
+	}
+
+	return string(content), nil
+}
+
+func fake(field string) (any, error) {
+	pick := func(values []string) string { return values[rand.IntN(len(values))] }
+
+	switch field {
+	case "firstName":
+		return pick(fakeFirstNames), nil
+	case "lastName":
+		return pick(fakeLastNames), nil
+	case "name":
+		return pick(fakeFirstNames) + " " + pick(fakeLastNames), nil
+	case "username":
+		return fmt.Sprintf("%s%d", strings.ToLower(pick(fakeFirstNames)), rand.IntN(10000)), nil
+	case "email":
+		return fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(pick(fakeFirstNames)), strings.ToLower(pick(fakeLastNames)), rand.IntN(10000)), nil
+	case "phone":
+		return fmt.Sprintf("+44 7%03d %06d", rand.IntN(1000), rand.IntN(1000000)), nil
+	case "city":
+		return pick(fakeCities), nil
+	case "word":
+		return pick(fakeWords), nil
+	}
+
+	return nil, fmt.Errorf("unknown faker field %q, use email, name, firstName, lastName, username, phone, city or word", field)
+}
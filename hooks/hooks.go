@@ -3,156 +3,112 @@ package hooks_logic
 import (
 	"encoding/json"
 	"fmt"
-	"net/url"
-	"os"
-	"path/filepath"
+	"maps"
+	"slices"
 	"strings"
 
-	"github.com/alexplayer15/parmesan/errors"
-	"github.com/alexplayer15/parmesan/request_sender"
-	"gopkg.in/yaml.v3"
+	oas_struct "github.com/alexplayer15/parmesan/data"
 )
 
 type HooksFile []HookEntry
+
+// HookEntry modifies the requests it matches. Requests are matched by path,
+// which may be an OAS path template or a glob, by pathRegex, operationId or tag,
+// and by method, where * or no method matches any. Response checks the
+// responses to the requests it matches and captures values from them. Script
+// is Starlark run on the request after everything else is applied. An entry
+// with include is replaced by the hooks of another file when the file is loaded.
 type HookEntry struct {
-	Path   string         `yaml:"path"`
-	Method string         `yaml:"method"`
-	Body   map[string]any `yaml:"body"`
+	Path        string            `yaml:"path"`
+	PathRegex   string            `yaml:"pathRegex"`
+	OperationId string            `yaml:"operationId"`
+	Tag         string            `yaml:"tag"`
+	Method      string            `yaml:"method"`
+	Body        map[string]any    `yaml:"body"`
+	Remove      []string          `yaml:"remove"`
+	Headers     map[string]string `yaml:"headers"`
+	Query       map[string]string `yaml:"query"`
+	PathParams  map[string]string `yaml:"pathParams"`
+	Cookies     map[string]string `yaml:"cookies"`
+	Script      string            `yaml:"script"`
+	Response    *ResponseHook     `yaml:"response"`
+	Include     string            `yaml:"include"`
+
+	//baseDir is the directory of the hooks file, which file() reads relative to
+	baseDir string
 }
 
 func (h HookEntry) IsEmpty() bool {
-	return h.Path == "" && h.Method == "" && len(h.Body) == 0
+	return h.Path == "" && h.PathRegex == "" && h.OperationId == "" && h.Tag == "" && h.Method == "" && h.Include == "" &&
+		h.Script == "" && !h.ModifiesBody() && !h.ModifiesParameters() && h.Response.IsEmpty()
 }
 
-func UnmarshalHooksFile(hooks string) (HooksFile, error) {
-	hooksContent, err := os.ReadFile(hooks)
-	if err != nil {
-		return HooksFile{}, fmt.Errorf("failed to read hooks file %s", hooks)
-	}
-
-	ext := strings.TrimPrefix(filepath.Ext(hooks), ".")
-
-	if ext != "yml" && ext != "yaml" {
-		return HooksFile{}, fmt.Errorf("hooks file must be YAML, you entered a %s file", ext)
-	}
-
-	var hooksFile HooksFile
-
-	if err := yaml.Unmarshal(hooksContent, &hooksFile); err != nil {
-		return HooksFile{}, fmt.Errorf("invalid YAML: %w", err)
-	}
-
-	return hooksFile, nil
+func (h HookEntry) ModifiesBody() bool {
+	return len(h.Body) > 0 || len(h.Remove) > 0
 }
 
-func TryAndFindHookForThisRequest(hooks HooksFile, req request_sender.Request) HookEntry {
-
-	//URL has already been validated so no need to return an error
-	parsedURL, _ := url.Parse(req.Url)
-
-	for _, hook := range hooks {
-		if hook.Path == parsedURL.Path && hook.Method == req.Method {
-			return hook
-		}
-	}
-
-	return HookEntry{}
+func (h HookEntry) ModifiesParameters() bool {
+	return len(h.Headers) > 0 || len(h.Query) > 0 || len(h.PathParams) > 0 || len(h.Cookies) > 0
 }
 
+// ModifyRequestBodyUsingHook sets the hook's body fields and then deletes its
+// remove fields. Keys are dot separated and can index into arrays, as in
+// items[2].sku, while items.sku sets sku on every element of items. Keys can
+// also be JSON Pointers such as /items/2/sku, or JSONPaths such as
+// $.items[?(@.type=='primary')].value, which change every value they select.
+//
+// Without a schema a value replacing a field must have the same JSON type as
+// the generated one. ModifyRequestBodyUsingSchema checks against the OAS instead.
 func ModifyRequestBodyUsingHook(matchingHook HookEntry, requestBody string) (string, error) {
-	var bodyMap map[string]any
-	if err := json.Unmarshal([]byte(requestBody), &bodyMap); err != nil {
-		return "", fmt.Errorf("failed to parse request body: %w", err)
-	}
-
-	for key, val := range matchingHook.Body {
-		// Support nested keys separated by dots
-		keys := strings.Split(key, ".")
-		if err := updateField(bodyMap, keys, val, []string{}); err != nil {
-			return "", fmt.Errorf("failed to apply hook for field '%s': %w", key, err)
-		}
-	}
-
-	updatedBody, err := json.MarshalIndent(bodyMap, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to re-encode modified body: %w", err)
-	}
+	return modifyRequestBody(matchingHook, requestBody, checkAgainstGeneratedValue)
+}
 
-	return string(updatedBody), nil
+// ModifyRequestBodyUsingSchema modifies the body like ModifyRequestBodyUsingHook
+// and checks every value the hook sets against the request body schema.
+func ModifyRequestBodyUsingSchema(matchingHook HookEntry, requestBody string, oas oas_struct.OAS, schema oas_struct.Schema) (string, error) {
+	return modifyRequestBody(matchingHook, requestBody, schemaCheck(oas, schema))
 }
 
-func updateField(bodyMap map[string]any, keys []string, newVal any, pathSoFar []string) error {
-	if len(keys) == 0 {
-		//warn user if hooks they have entered for this path are empty. to do: add in log level flag.
-		return nil
+func modifyRequestBody(matchingHook HookEntry, requestBody string, check valueCheck) (string, error) {
+	var body any = map[string]any{}
+	if strings.TrimSpace(requestBody) != "" {
+		if err := json.Unmarshal([]byte(requestBody), &body); err != nil {
+			return "", fmt.Errorf("failed to parse request body: %w", err)
+		}
 	}
 
-	currentKey := keys[0]
-	pathSoFar = append(pathSoFar, currentKey)
-
-	if len(keys) == 1 {
-		existingVal, exists := bodyMap[currentKey]
-		if !exists {
-			return errors.NewMissingHookFieldError(strings.Join(pathSoFar, "."))
+	//sorted so a.b is applied after a when a hook sets both
+	for _, key := range slices.Sorted(maps.Keys(matchingHook.Body)) {
+		locations, err := resolveHookKey(body, key)
+		if err != nil {
+			return "", fmt.Errorf("failed to apply hook for field '%s': %w", key, err)
 		}
-
-		if err := validateHookTypesAgainstRequestSchema(existingVal, newVal); err != nil {
-			return err
+		for _, tokens := range locations {
+			body, err = setField(body, tokens, matchingHook.Body[key], nil, check)
+			if err != nil {
+				return "", fmt.Errorf("failed to apply hook for field '%s': %w", key, err)
+			}
 		}
-
-		bodyMap[currentKey] = newVal
-		return nil
-	}
-
-	next, exists := bodyMap[currentKey]
-	if !exists {
-		return errors.NewMissingHookFieldError(strings.Join(pathSoFar, "."))
 	}
 
-	switch typed := next.(type) {
-	case map[string]any:
-		return updateField(typed, keys[1:], newVal, pathSoFar)
-
-	case []any:
-		for i, item := range typed {
-			if itemMap, ok := item.(map[string]any); ok {
-				err := updateField(itemMap, keys[1:], newVal, append(pathSoFar, fmt.Sprintf("[%d]", i)))
-				if err != nil {
-					return err
-				}
-				typed[i] = itemMap
+	for _, key := range matchingHook.Remove {
+		locations, err := resolveHookKey(body, key)
+		if err != nil {
+			return "", fmt.Errorf("failed to remove field '%s': %w", key, err)
+		}
+		//last first, so removing an array element does not move the ones still to remove
+		for i := len(locations) - 1; i >= 0; i-- {
+			body, err = removeField(body, locations[i], nil)
+			if err != nil {
+				return "", fmt.Errorf("failed to remove field '%s': %w", key, err)
 			}
 		}
-		bodyMap[currentKey] = typed
-		return nil
-
-	default:
-		return errors.NewMissingHookFieldError(strings.Join(pathSoFar, "."))
 	}
-}
 
-func validateHookTypesAgainstRequestSchema(original any, newVal any) error {
-	switch original.(type) {
-	case string:
-		_, ok := newVal.(string)
-		if !ok {
-			return fmt.Errorf("type mismatch: expected string, got %T", newVal)
-		}
-	case int:
-		_, ok := newVal.(float64)
-		if !ok {
-			return fmt.Errorf("type mismatch: expected number, got %T", newVal)
-		}
-	case bool:
-		_, ok := newVal.(bool)
-		if !ok {
-			return fmt.Errorf("type mismatch: expected boolean, got %T", newVal)
-		}
-	case []any, map[string]any:
-		return fmt.Errorf("modifying arrays or objects is not supported")
-	default:
-		return fmt.Errorf("unsupported target type %T", original)
+	updatedBody, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to re-encode modified body: %w", err)
 	}
 
-	return nil
+	return string(updatedBody), nil
 }
@@ -0,0 +1,408 @@
+package hooks_logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/request_generator"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/schema_validator"
+	"gopkg.in/yaml.v3"
+)
+
+// mergeKey merges the keys of another YAML mapping into a hook.
+const mergeKey = "<<"
+
+// hookKeys are the keys a hook may have, in the order they are documented.
+var hookKeys = []string{"path", "pathRegex", "operationId", "tag", "method", "body", "remove", "headers", "query", "pathParams", "cookies", "script", "response", "include"}
+
+// LintIssue is a problem in a hooks file, at the line and column of the YAML
+// or JSON it was found in.
+type LintIssue struct {
+	File   string
+	Line   int
+	Column int
+	Hook   string
+	Msg    string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", i.Line, i.Column, i.Hook, i.Msg)
+}
+
+// LintHooksFile checks hooks against an OAS without sending anything. hooks is
+// a file or directory as UnmarshalHooksFile takes, and included files are
+// checked too. Every hook must select at least one operation, and every field
+// it sets must be declared by the schema or parameters of each operation it
+// selects, with a value the schema accepts. Values with ${function()} or
+// {{variable}} in them are only known when sending, so only their field is checked.
+func LintHooksFile(oas oas_struct.OAS, hooks string) ([]LintIssue, error) {
+	return lintHooks(oas, hooks, map[string]bool{})
+}
+
+// lintHooks checks the hooks at path. linted holds the files already checked,
+// so a file included twice is only reported once.
+func lintHooks(oas oas_struct.OAS, path string, linted map[string]bool) ([]LintIssue, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks file %s", path)
+	}
+
+	if info.IsDir() {
+		files, err := hookFilesIn(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var issues []LintIssue
+		for _, file := range files {
+			fileIssues, err := lintHooks(oas, file, linted)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, fileIssues...)
+		}
+		return issues, nil
+	}
+
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks file %s: %w", path, err)
+	}
+	if linted[absolute] {
+		return nil, nil
+	}
+	linted[absolute] = true
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks file %s", path)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if !slices.Contains(hookFileExtensions, ext) {
+		return nil, fmt.Errorf("hooks file must be YAML or JSON, you entered a %s file", strings.TrimPrefix(ext, "."))
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("invalid hooks file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.SequenceNode {
+		return []LintIssue{{File: path, Line: root.Line, Column: root.Column, Hook: "hooks file", Msg: "must be a list of hooks"}}, nil
+	}
+
+	var issues []LintIssue
+	for i, item := range root.Content {
+		include := mapValue(item, "include")
+		if include == nil {
+			issues = append(issues, lintHook(oas, path, i, item)...)
+			continue
+		}
+
+		if len(item.Content) > 2 {
+			issues = append(issues, LintIssue{File: path, Line: item.Line, Column: item.Column, Hook: fmt.Sprintf("hook %d", i+1), Msg: "include cannot be combined with other keys"})
+		}
+
+		includePath := resolveInclude(path, include.Value)
+		if _, err := os.Stat(includePath); err != nil {
+			issues = append(issues, LintIssue{File: path, Line: include.Line, Column: include.Column, Hook: fmt.Sprintf("hook %d", i+1), Msg: fmt.Sprintf("cannot read included hooks %s", includePath)})
+			continue
+		}
+		included, err := lintHooks(oas, includePath, linted)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, included...)
+	}
+	return issues, nil
+}
+
+func lintHook(oas oas_struct.OAS, file string, index int, item *yaml.Node) []LintIssue {
+	var hook HookEntry
+	if err := item.Decode(&hook); err != nil {
+		return []LintIssue{{File: file, Line: item.Line, Column: item.Column, Hook: fmt.Sprintf("hook %d", index+1), Msg: err.Error()}}
+	}
+	hook.baseDir = filepath.Dir(file)
+
+	var issues []LintIssue
+	report := func(node *yaml.Node, format string, args ...any) {
+		//keys arriving through a merge key have no node of their own in the hook
+		node = firstNonNil(node, item)
+		issues = append(issues, LintIssue{
+			File:   file,
+			Line:   node.Line,
+			Column: node.Column,
+			Hook:   fmt.Sprintf("hook %d (%s)", index+1, hook.Describe()),
+			Msg:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	for i := 0; i < len(item.Content); i += 2 {
+		if key := item.Content[i]; key.Value != mergeKey && !slices.Contains(hookKeys, key.Value) {
+			report(key, "unknown key %q, hooks can have %s", key.Value, strings.Join(hookKeys, ", "))
+		}
+	}
+
+	if err := hook.validate(); err != nil {
+		report(item, "%v", err)
+		return issues
+	}
+
+	ops := hook.operations(oas)
+	if len(ops) == 0 {
+		node, msg := hook.unmatchedReason(oas, item)
+		report(node, "%s", msg)
+		return issues
+	}
+
+	for _, op := range ops {
+		//a hook selecting several operations may set body defaults that only some of them take
+		_, hasBody := RequestBodySchema(op)
+		if hook.ModifiesBody() && (hasBody || len(ops) == 1) {
+			for _, problem := range lintBody(oas, op, hook, item) {
+				report(problem.node, "%s: %s", op.Name(), problem.msg)
+			}
+		}
+
+		sections := []struct {
+			in     string
+			key    string
+			values map[string]string
+		}{
+			{"header", "headers", hook.Headers},
+			{"query", "query", hook.Query},
+			{"path", "pathParams", hook.PathParams},
+			{"cookie", "cookies", hook.Cookies},
+		}
+		for _, section := range sections {
+			for _, name := range slices.Sorted(maps.Keys(section.values)) {
+				single := HookEntry{}
+				single.setParameter(section.in, name, section.values[name])
+				if err := ValidateHookParameters(single, op, request_sender.Request{Headers: map[string]string{}}); err != nil {
+					report(mapKey(mapValue(item, section.key), name), "%s does not declare a %s parameter %s", op.Name(), section.in, name)
+				}
+			}
+		}
+	}
+
+	return issues
+}
+
+// operations returns every operation in the OAS the hook selects. Paths are
+// matched with and without each server's base path, as they are when sending.
+func (h HookEntry) operations(oas oas_struct.OAS) []operations.Operation {
+	var selected []operations.Operation
+	for _, op := range operations.ListOperations(oas) {
+		if h.matches(op.Method, operationPaths(oas, op), op) {
+			selected = append(selected, op)
+		}
+	}
+	return selected
+}
+
+func operationPaths(oas oas_struct.OAS, op operations.Operation) []string {
+	paths := []string{op.Path}
+	for _, server := range oas.Servers {
+		parsedServer, err := url.Parse(server.URL)
+		if err != nil {
+			continue
+		}
+		if basePath := strings.TrimRight(parsedServer.Path, "/"); basePath != "" {
+			paths = append(paths, basePath+op.Path)
+		}
+	}
+	return paths
+}
+
+// setParameter sets one parameter of the kind in, as the OAS names them.
+func (h *HookEntry) setParameter(in string, name string, value string) {
+	param := map[string]string{name: value}
+	switch in {
+	case "header":
+		h.Headers = param
+	case "query":
+		h.Query = param
+	case "path":
+		h.PathParams = param
+	case "cookie":
+		h.Cookies = param
+	}
+}
+
+// unmatchedReason explains why a hook selects no operation, pointing at the
+// key responsible.
+func (h HookEntry) unmatchedReason(oas oas_struct.OAS, item *yaml.Node) (*yaml.Node, string) {
+	anyMethod := h
+	anyMethod.Method = ""
+	if h.Method != "" && len(anyMethod.operations(oas)) > 0 {
+		return mapValue(item, "method"), fmt.Sprintf("none of the operations the hook selects use method %s", strings.ToUpper(h.Method))
+	}
+
+	switch {
+	case h.OperationId != "":
+		if _, ok := operations.FindByOperationId(oas, h.OperationId); !ok {
+			return mapValue(item, "operationId"), fmt.Sprintf("no operation has operationId %s", h.OperationId)
+		}
+	case h.Tag != "":
+		if len((HookEntry{Tag: h.Tag}).operations(oas)) == 0 {
+			return mapValue(item, "tag"), fmt.Sprintf("no operation is tagged %s", h.Tag)
+		}
+	}
+
+	switch {
+	case h.Path != "":
+		return mapValue(item, "path"), fmt.Sprintf("path %s matches no operation in the OAS", h.Path)
+	case h.PathRegex != "":
+		return mapValue(item, "pathRegex"), fmt.Sprintf("pathRegex %s matches no operation in the OAS", h.PathRegex)
+	case h.Method != "":
+		return mapValue(item, "method"), fmt.Sprintf("no operation uses method %s", strings.ToUpper(h.Method))
+	}
+	return item, "the hook selects no operation in the OAS"
+}
+
+type lintProblem struct {
+	node *yaml.Node
+	msg  string
+}
+
+// lintBody checks the body fields a hook sets and removes on one operation.
+// Keys are resolved against a body generated from the OAS examples, so
+// JSONPaths select what they would when sending.
+func lintBody(oas oas_struct.OAS, op operations.Operation, hook HookEntry, item *yaml.Node) []lintProblem {
+	bodyNode, removeNode := mapValue(item, "body"), mapValue(item, "remove")
+
+	schema, ok := RequestBodySchema(op)
+	if !ok {
+		return []lintProblem{{node: firstNonNil(bodyNode, removeNode, item), msg: "the operation has no JSON request body"}}
+	}
+
+	var body any = map[string]any{}
+	if _, isJSON := op.Details.RequestBody.Content["application/json"]; isJSON {
+		generated, err := request_generator.GenerateRequestBody(oas, op.Path, op.Method)
+		if err == nil && strings.TrimSpace(generated) != "" {
+			_ = json.Unmarshal([]byte(generated), &body)
+		}
+	}
+
+	var problems []lintProblem
+	check := func(node *yaml.Node, key string, value any, setsValue bool) {
+		locations, err := resolveHookKey(body, key)
+		if err != nil {
+			problems = append(problems, lintProblem{node: node, msg: err.Error()})
+			return
+		}
+		for _, tokens := range locations {
+			sub, err := lintSchemaAt(oas, schema, tokens)
+			if err != nil {
+				problems = append(problems, lintProblem{node: node, msg: fmt.Sprintf("body field %s: %v", formatKey(tokens), err)})
+				continue
+			}
+			if !setsValue || isDynamic(value) {
+				continue
+			}
+			for _, violation := range schema_validator.Validate(sub, value, oas, formatKey(tokens)) {
+				problems = append(problems, lintProblem{node: node, msg: "body field " + violation.String()})
+			}
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(hook.Body)) {
+		check(mapKey(bodyNode, key), key, hook.Body[key], true)
+	}
+	for i, key := range hook.Remove {
+		node := removeNode
+		if removeNode != nil && removeNode.Kind == yaml.SequenceNode && i < len(removeNode.Content) {
+			node = removeNode.Content[i]
+		}
+		check(node, key, nil, false)
+	}
+
+	return problems
+}
+
+// lintSchemaAt walks the body schema along a hook key like schemaAt, but a
+// field the schema does not declare is reported even where it would be
+// allowed, since it is most likely a typo. Names reached through an array
+// apply to its items, as they do when sending.
+func lintSchemaAt(oas oas_struct.OAS, schema oas_struct.Schema, path []keyToken) (oas_struct.Schema, error) {
+	for _, token := range path {
+		resolved, err := oas.ResolveSchema(schema)
+		if err != nil {
+			return oas_struct.Schema{}, err
+		}
+
+		if token.isIndex || token.isAppend {
+			if schema, err = schema_validator.ItemSchema(oas, resolved); err != nil {
+				return oas_struct.Schema{}, err
+			}
+			continue
+		}
+
+		if resolved.Type == "array" && resolved.Items != nil {
+			resolved = *resolved.Items
+		}
+		if schema, err = schema_validator.PropertySchema(oas, resolved, token.name); err != nil {
+			return oas_struct.Schema{}, err
+		}
+		if !schema_validator.HasProperty(oas, resolved, token.name) && !declaresAdditionalProperties(oas, resolved) {
+			return oas_struct.Schema{}, fmt.Errorf("%s is not defined in the request body schema", token.name)
+		}
+	}
+	return schema, nil
+}
+
+func declaresAdditionalProperties(oas oas_struct.OAS, schema oas_struct.Schema) bool {
+	resolved, err := oas.ResolveSchema(schema)
+	return err == nil && resolved.AdditionalProperties != nil && resolved.AdditionalProperties.Schema != nil
+}
+
+// mapValue returns the value of key in a YAML mapping, following an alias to
+// the node it refers to, or nil. Keys merged in with << are not found.
+func mapValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value := mapping.Content[i+1]
+			if value.Kind == yaml.AliasNode && value.Alias != nil {
+				return value.Alias
+			}
+			return value
+		}
+	}
+	return nil
+}
+
+// mapKey returns the node of key in a YAML mapping, or the mapping itself.
+func mapKey(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; mapping != nil && i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i]
+		}
+	}
+	return mapping
+}
+
+func firstNonNil(nodes ...*yaml.Node) *yaml.Node {
+	for _, node := range nodes {
+		if node != nil {
+			return node
+		}
+	}
+	return nil
+}
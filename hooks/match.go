@@ -0,0 +1,254 @@
+package hooks_logic
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/request_sender"
+)
+
+// Hooks matching a request are applied from the least to the most specific, so
+// a more specific hook overrides what a broader one sets.
+const (
+	precedenceGlobal = iota
+	precedenceTag
+	precedencePattern
+	precedenceSpecific
+)
+
+// precedence ranks how specifically a hook picks its requests. A hook naming
+// no path, operationId or tag is a global default for every request.
+func (h HookEntry) precedence() int {
+	switch {
+	case h.OperationId != "" || (h.Path != "" && !isGlob(h.Path)):
+		return precedenceSpecific
+	case h.Path != "" || h.PathRegex != "":
+		return precedencePattern
+	case h.Tag != "":
+		return precedenceTag
+	}
+	return precedenceGlobal
+}
+
+// Describe names what the hook matches, for output.
+func (h HookEntry) Describe() string {
+	method := strings.ToUpper(h.Method)
+	if method == "" {
+		method = "*"
+	}
+
+	var targets []string
+	if h.OperationId != "" {
+		targets = append(targets, "operationId "+h.OperationId)
+	}
+	if h.Tag != "" {
+		targets = append(targets, "tag "+h.Tag)
+	}
+	if h.Path != "" {
+		targets = append(targets, h.Path)
+	}
+	if h.PathRegex != "" {
+		targets = append(targets, "~"+h.PathRegex)
+	}
+	if len(targets) == 0 {
+		targets = append(targets, "all requests")
+	}
+
+	return method + " " + strings.Join(targets, " ")
+}
+
+// Validate checks the parts of each hook that can be wrong before any request
+// is matched against it.
+func (hooks HooksFile) Validate() error {
+	for i, hook := range hooks {
+		if err := hook.validate(); err != nil {
+			return fmt.Errorf("hook %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (h HookEntry) validate() error {
+	if h.PathRegex != "" {
+		if _, err := regexp.Compile(h.PathRegex); err != nil {
+			return fmt.Errorf("invalid pathRegex: %w", err)
+		}
+	}
+	if h.Path != "" && !strings.HasPrefix(h.Path, "/") {
+		return fmt.Errorf("path %q must start with /", h.Path)
+	}
+	if _, err := h.RequestScript(); err != nil {
+		return err
+	}
+	if _, err := h.ResponseScript(); err != nil {
+		return fmt.Errorf("response %w", err)
+	}
+	return h.Response.validate()
+}
+
+// FindHooksForRequest returns every hook matching the request, least specific
+// first and in file order among equally specific hooks. op is the request's
+// operation, or the zero Operation when it is not known, in which case hooks
+// selecting by operationId or tag cannot match.
+func FindHooksForRequest(hooks HooksFile, req request_sender.Request, op operations.Operation, serverURL string) []HookEntry {
+	paths := requestPaths(req, serverURL)
+
+	var matching []HookEntry
+	for _, hook := range hooks {
+		if hook.matches(req.Method, paths, op) {
+			matching = append(matching, hook)
+		}
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].precedence() < matching[j].precedence()
+	})
+
+	return matching
+}
+
+// TryAndFindHookForThisRequest merges every hook matching the request into one,
+// or returns an empty hook if none match.
+func TryAndFindHookForThisRequest(hooks HooksFile, req request_sender.Request, op operations.Operation, serverURL string) HookEntry {
+	return MergeHooks(FindHooksForRequest(hooks, req, op, serverURL))
+}
+
+// MergeHooks combines hooks in order, later hooks overriding the values of
+// earlier ones. Fields to remove are collected from all of them.
+func MergeHooks(hooks []HookEntry) HookEntry {
+	switch len(hooks) {
+	case 0:
+		return HookEntry{}
+	case 1:
+		return hooks[0]
+	}
+
+	merged := HookEntry{Path: hooks[len(hooks)-1].Path, Method: hooks[len(hooks)-1].Method}
+	for _, hook := range hooks {
+		merged.Body = mergeMap(merged.Body, hook.Body)
+		merged.Headers = mergeMap(merged.Headers, hook.Headers)
+		merged.Query = mergeMap(merged.Query, hook.Query)
+		merged.PathParams = mergeMap(merged.PathParams, hook.PathParams)
+		merged.Cookies = mergeMap(merged.Cookies, hook.Cookies)
+		merged.Remove = append(merged.Remove, hook.Remove...)
+		merged.Response = mergeResponse(merged.Response, hook.Response)
+	}
+
+	return merged
+}
+
+func mergeMap[V any](into map[string]V, from map[string]V) map[string]V {
+	if len(from) == 0 {
+		return into
+	}
+	if into == nil {
+		into = map[string]V{}
+	}
+	maps.Copy(into, from)
+	return into
+}
+
+func (h HookEntry) matches(method string, paths []string, op operations.Operation) bool {
+	if h.Method != "" && h.Method != "*" && !strings.EqualFold(h.Method, method) {
+		return false
+	}
+
+	if h.OperationId != "" && h.OperationId != op.Details.OperationId {
+		return false
+	}
+	if h.Tag != "" && !slices.Contains(op.Details.Tags, h.Tag) {
+		return false
+	}
+
+	if h.Path != "" {
+		matched := op.Path != "" && h.Path == op.Path
+		for _, requestPath := range paths {
+			matched = matched || matchHookPath(h.Path, requestPath)
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if h.PathRegex != "" {
+		pattern, err := regexp.Compile(h.PathRegex)
+		if err != nil {
+			return false
+		}
+		if !slices.ContainsFunc(paths, pattern.MatchString) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// requestPaths returns the request's URL path, and its path within the server
+// when that differs, so hooks can be written either way.
+func requestPaths(req request_sender.Request, serverURL string) []string {
+	//URL has already been validated so no need to return an error
+	parsedURL, _ := url.Parse(req.Url)
+	paths := []string{parsedURL.Path}
+
+	if parsedServer, err := url.Parse(serverURL); err == nil && serverURL != "" {
+		if withinServer, ok := operations.TrimBasePath(parsedURL.Path, parsedServer.Path); ok && withinServer != parsedURL.Path {
+			paths = append(paths, withinServer)
+		}
+	}
+
+	return paths
+}
+
+// matchHookPath matches a request path against a hook path, which is either an
+// OAS path template such as /users/{id} or a glob such as /admin/**.
+func matchHookPath(hookPath string, requestPath string) bool {
+	if isGlob(hookPath) {
+		return matchGlob(splitPath(hookPath), splitPath(requestPath))
+	}
+	_, ok := operations.MatchPathTemplate(hookPath, requestPath)
+	return ok
+}
+
+func isGlob(hookPath string) bool {
+	return strings.Contains(hookPath, "*")
+}
+
+func splitPath(p string) []string {
+	trimmed := strings.Trim(p, "/")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "/")
+}
+
+// matchGlob matches path segments where * matches within one segment and a **
+// segment matches any number of segments, including none.
+func matchGlob(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(segments); skip++ {
+			if matchGlob(pattern[1:], segments[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+		return false
+	}
+	return matchGlob(pattern[1:], segments[1:])
+}
@@ -0,0 +1,215 @@
+package hooks_logic
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/request_sender"
+)
+
+// headersDefinedElsewhere are headers the OAS says are not described as
+// parameters, so a hook may set them on any operation.
+var headersDefinedElsewhere = []string{"Accept", "Content-Type", "Authorization"}
+
+// ValidateHookParameters checks that every header, query, path and cookie
+// parameter the hook sets is declared on the operation. Headers already on the
+// generated request are allowed too.
+func ValidateHookParameters(hook HookEntry, op operations.Operation, req request_sender.Request) error {
+	declared := func(in string, name string) bool {
+		return slices.ContainsFunc(op.Details.Parameters, func(param oas_struct.Parameter) bool {
+			if in == "header" {
+				return param.In == in && http.CanonicalHeaderKey(param.Name) == http.CanonicalHeaderKey(name)
+			}
+			return param.In == in && param.Name == name
+		})
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(hook.Headers)) {
+		canonical := http.CanonicalHeaderKey(name)
+		if declared("header", name) || slices.Contains(headersDefinedElsewhere, canonical) || hasHeader(req, name) {
+			continue
+		}
+		return errors.NewUnknownHookParameterError("headers."+name, hook.Headers[name], "header", op.Name())
+	}
+
+	sections := []struct {
+		in     string
+		key    string
+		values map[string]string
+	}{
+		{"query", "query", hook.Query},
+		{"path", "pathParams", hook.PathParams},
+		{"cookie", "cookies", hook.Cookies},
+	}
+	for _, section := range sections {
+		for _, name := range slices.Sorted(maps.Keys(section.values)) {
+			if declared(section.in, name) {
+				continue
+			}
+			//path parameters are often only written in the path template
+			if section.in == "path" && strings.Contains(op.Path, "{"+name+"}") {
+				continue
+			}
+			return errors.NewUnknownHookParameterError(section.key+"."+name, section.values[name], section.in, op.Name())
+		}
+	}
+
+	return nil
+}
+
+// ModifyRequestParametersUsingHook sets the hook's headers, query parameters,
+// path parameters and cookies on the request. pathTemplate is the path of the
+// request's operation, which locates path parameters that were already filled
+// in. Without it only {param} placeholders still in the URL can be replaced.
+func ModifyRequestParametersUsingHook(hook HookEntry, req request_sender.Request, pathTemplate string) (request_sender.Request, error) {
+	headers := make(map[string]string, len(req.Headers))
+	maps.Copy(headers, req.Headers)
+	req.Headers = headers
+
+	for name, value := range hook.Headers {
+		setHeader(req, name, value)
+	}
+
+	if len(hook.Cookies) > 0 {
+		setHeader(req, "Cookie", mergeCookies(getHeader(req, "Cookie"), hook.Cookies))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(hook.PathParams)) {
+		filled, ok := fillPathParam(req.Url, pathTemplate, name, hook.PathParams[name])
+		if !ok {
+			return req, errors.NewHookError("pathParams."+name, hook.PathParams[name], errors.ErrCodeHookParameterDoesNotExist, fmt.Sprintf("%s has no path parameter %s", req.Url, name))
+		}
+		req.Url = filled
+	}
+
+	if len(hook.Query) > 0 {
+		parsedURL, err := url.Parse(req.Url)
+		if err != nil {
+			return req, fmt.Errorf("failed to parse URL %s: %w", req.Url, err)
+		}
+		query := parsedURL.Query()
+		for name, value := range hook.Query {
+			query.Set(name, value)
+		}
+		parsedURL.RawQuery = query.Encode()
+		req.Url = parsedURL.String()
+	}
+
+	return req, nil
+}
+
+// fillPathParam replaces the URL path segment the template puts name in. The
+// template is lined up with the end of the URL path, which may also hold the
+// server's base path, ignoring a trailing slash on either. The template's
+// literal segments must match the ones they line up with.
+func fillPathParam(rawURL string, pathTemplate string, name string, value string) (string, bool) {
+	placeholder := "{" + name + "}"
+	if pathTemplate == "" || !strings.Contains(pathTemplate, placeholder) {
+		if !strings.Contains(rawURL, placeholder) {
+			return rawURL, false
+		}
+		return strings.ReplaceAll(rawURL, placeholder, url.PathEscape(value)), true
+	}
+
+	origin, path, suffix := splitURL(rawURL)
+	trimmed := path
+	if len(path) > 1 {
+		trimmed = strings.TrimSuffix(path, "/")
+	}
+	pathSegments := strings.Split(trimmed, "/")
+	templateSegments := strings.Split(strings.Trim(pathTemplate, "/"), "/")
+
+	offset := len(pathSegments) - len(templateSegments)
+	if offset < 0 {
+		return rawURL, false
+	}
+	for i, segment := range templateSegments {
+		switch {
+		case segment == placeholder:
+			pathSegments[offset+i] = url.PathEscape(value)
+		case !operations.PathParamPattern.MatchString(segment) && segment != pathSegments[offset+i]:
+			return rawURL, false
+		}
+	}
+
+	return origin + strings.Join(pathSegments, "/") + path[len(trimmed):] + suffix, true
+}
+
+// splitURL splits a URL into everything before its path, its path and its
+// query and fragment, without decoding anything.
+func splitURL(rawURL string) (string, string, string) {
+	origin := ""
+	rest := rawURL
+	if scheme, afterScheme, ok := strings.Cut(rawURL, "://"); ok {
+		//the host ends at whichever of the path, query or fragment comes first
+		hostEnd := strings.IndexAny(afterScheme, "/?#")
+		if hostEnd == -1 {
+			hostEnd = len(afterScheme)
+		}
+		origin = scheme + "://" + afterScheme[:hostEnd]
+		rest = afterScheme[hostEnd:]
+		if !strings.HasPrefix(rest, "/") {
+			rest = "/" + rest
+		}
+	}
+
+	end := strings.IndexAny(rest, "?#")
+	if end == -1 {
+		return origin, rest, ""
+	}
+	return origin, rest[:end], rest[end:]
+}
+
+// mergeCookies sets cookies on a Cookie header, replacing any with the same name.
+func mergeCookies(header string, cookies map[string]string) string {
+	var pairs []string
+	for _, pair := range strings.Split(header, ";") {
+		pair = strings.TrimSpace(pair)
+		name, _, _ := strings.Cut(pair, "=")
+		if _, replaced := cookies[name]; pair == "" || replaced {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cookies)) {
+		pairs = append(pairs, name+"="+cookies[name])
+	}
+
+	return strings.Join(pairs, "; ")
+}
+
+// headerName finds the name a header is stored under on the request, which may
+// differ in case from name.
+func headerName(req request_sender.Request, name string) (string, bool) {
+	for existing := range req.Headers {
+		if strings.EqualFold(existing, name) {
+			return existing, true
+		}
+	}
+	return "", false
+}
+
+func hasHeader(req request_sender.Request, name string) bool {
+	_, ok := headerName(req, name)
+	return ok
+}
+
+func getHeader(req request_sender.Request, name string) string {
+	existing, _ := headerName(req, name)
+	return req.Headers[existing]
+}
+
+func setHeader(req request_sender.Request, name string, value string) {
+	if existing, ok := headerName(req, name); ok {
+		delete(req.Headers, existing)
+	}
+	req.Headers[name] = value
+}
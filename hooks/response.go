@@ -0,0 +1,241 @@
+package hooks_logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexplayer15/parmesan/jsonpath"
+	"github.com/alexplayer15/parmesan/request_sender"
+)
+
+// ResponseHook checks the responses to the requests a hook matches and
+// captures values from them into variables for later requests. Script is
+// Starlark run on each response, which can fail the request and set variables.
+type ResponseHook struct {
+	Status     []int             `yaml:"status"`
+	Body       []BodyAssertion   `yaml:"body"`
+	Headers    []string          `yaml:"headers"`
+	MaxLatency time.Duration     `yaml:"maxLatency"`
+	Capture    map[string]string `yaml:"capture"`
+	Script     string            `yaml:"script"`
+}
+
+// BodyAssertion checks the values a JSONPath selects from the response body.
+// Every selected value must equal equals, contain contains and match the
+// regular expression matches, whichever are given.
+type BodyAssertion struct {
+	Path     string `yaml:"path"`
+	Equals   any    `yaml:"equals"`
+	Contains any    `yaml:"contains"`
+	Matches  string `yaml:"matches"`
+}
+
+func (r *ResponseHook) IsEmpty() bool {
+	return r == nil || len(r.Status) == 0 && len(r.Body) == 0 && len(r.Headers) == 0 && r.MaxLatency == 0 && len(r.Capture) == 0 && r.Script == ""
+}
+
+// Assertions counts the checks Check makes.
+func (r *ResponseHook) Assertions() int {
+	if r == nil {
+		return 0
+	}
+	count := len(r.Body) + len(r.Headers)
+	if len(r.Status) > 0 {
+		count++
+	}
+	if r.MaxLatency > 0 {
+		count++
+	}
+	return count
+}
+
+func (r *ResponseHook) validate() error {
+	if r == nil {
+		return nil
+	}
+	for _, assertion := range r.Body {
+		if !jsonpath.IsPath(assertion.Path) {
+			return fmt.Errorf("response body assertion path %q must be a JSONPath such as $.id", assertion.Path)
+		}
+		if _, err := jsonpath.Query(nil, assertion.Path); err != nil {
+			return fmt.Errorf("response body assertion: %w", err)
+		}
+		if assertion.Equals == nil && assertion.Contains == nil && assertion.Matches == "" {
+			return fmt.Errorf("response body assertion on %s needs equals, contains or matches", assertion.Path)
+		}
+		if _, err := regexp.Compile(assertion.Matches); err != nil {
+			return fmt.Errorf("response body assertion on %s: invalid matches: %w", assertion.Path, err)
+		}
+	}
+	if r.MaxLatency < 0 {
+		return fmt.Errorf("response maxLatency must not be negative, got %s", r.MaxLatency)
+	}
+	return nil
+}
+
+// Check returns a message for every assertion the response fails.
+func (r *ResponseHook) Check(resp request_sender.Response) []string {
+	if r == nil {
+		return nil
+	}
+
+	var failures []string
+
+	if len(r.Status) > 0 && !slices.Contains(r.Status, resp.StatusCode) {
+		failures = append(failures, fmt.Sprintf("status %d is not one of %v", resp.StatusCode, r.Status))
+	}
+
+	for _, name := range r.Headers {
+		if len(resp.Headers.Values(name)) == 0 {
+			failures = append(failures, fmt.Sprintf("header %s is missing", name))
+		}
+	}
+
+	if r.MaxLatency > 0 && resp.Timing.Total > r.MaxLatency {
+		failures = append(failures, fmt.Sprintf("took %s, over the %s limit", resp.Timing.Total.Round(time.Millisecond), r.MaxLatency))
+	}
+
+	if len(r.Body) == 0 {
+		return failures
+	}
+
+	var body any
+	if err := json.Unmarshal([]byte(resp.Body), &body); err != nil {
+		for _, assertion := range r.Body {
+			failures = append(failures, fmt.Sprintf("%s: response body is not JSON", assertion.Path))
+		}
+		return failures
+	}
+
+	for _, assertion := range r.Body {
+		if failure := assertion.check(body); failure != "" {
+			failures = append(failures, assertion.Path+": "+failure)
+		}
+	}
+
+	return failures
+}
+
+func (a BodyAssertion) check(body any) string {
+	values, err := jsonpath.Query(body, a.Path)
+	if err != nil {
+		return err.Error()
+	}
+	if len(values) == 0 {
+		return "matched nothing in the response body"
+	}
+
+	for _, value := range values {
+		if a.Equals != nil && !jsonEqual(value, a.Equals) {
+			return fmt.Sprintf("expected %s, got %s", formatJSON(a.Equals), formatJSON(value))
+		}
+		if a.Contains != nil && !contains(value, a.Contains) {
+			return fmt.Sprintf("%s does not contain %s", formatJSON(value), formatJSON(a.Contains))
+		}
+		if a.Matches != "" {
+			pattern := regexp.MustCompile(a.Matches)
+			if text := fmt.Sprint(value); !pattern.MatchString(text) {
+				return fmt.Sprintf("%s does not match %s", formatJSON(value), a.Matches)
+			}
+		}
+	}
+	return ""
+}
+
+// contains checks a substring of a string, an element of an array or the
+// fields of an object.
+func contains(value any, expected any) bool {
+	switch typed := value.(type) {
+	case string:
+		text, ok := expected.(string)
+		return ok && strings.Contains(typed, text)
+	case []any:
+		return slices.ContainsFunc(typed, func(item any) bool { return jsonEqual(item, expected) })
+	case map[string]any:
+		fields, ok := normaliseJSON(expected).(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, field := range fields {
+			if actual, ok := typed[key]; !ok || !jsonEqual(actual, field) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// jsonEqual compares a value decoded from JSON with one decoded from YAML,
+// which disagree on how numbers are represented.
+func jsonEqual(actual any, expected any) bool {
+	return reflect.DeepEqual(normaliseJSON(actual), normaliseJSON(expected))
+}
+
+func normaliseJSON(value any) any {
+	switch typed := value.(type) {
+	case int:
+		return float64(typed)
+	case int64:
+		return float64(typed)
+	case uint64:
+		return float64(typed)
+	case map[string]any:
+		normalised := make(map[string]any, len(typed))
+		for key, item := range typed {
+			normalised[key] = normaliseJSON(item)
+		}
+		return normalised
+	case []any:
+		normalised := make([]any, len(typed))
+		for i, item := range typed {
+			normalised[i] = normaliseJSON(item)
+		}
+		return normalised
+	}
+	return value
+}
+
+func formatJSON(value any) string {
+	encoded, err := json.Marshal(normaliseJSON(value))
+	if err != nil {
+		return strconv.Quote(fmt.Sprint(value))
+	}
+	return string(encoded)
+}
+
+// mergeResponse combines response hooks in order. Later hooks override the
+// status set and latency limit, assertions are collected from all of them and
+// captures are merged. Scripts are not merged, each hook's runs on its own.
+func mergeResponse(into *ResponseHook, from *ResponseHook) *ResponseHook {
+	if from == nil {
+		return into
+	}
+	if into == nil {
+		into = &ResponseHook{}
+	}
+
+	if len(from.Status) > 0 {
+		into.Status = from.Status
+	}
+	if from.MaxLatency > 0 {
+		into.MaxLatency = from.MaxLatency
+	}
+	into.Body = append(into.Body, from.Body...)
+	into.Headers = append(into.Headers, from.Headers...)
+	if len(from.Capture) > 0 {
+		if into.Capture == nil {
+			into.Capture = map[string]string{}
+		}
+		maps.Copy(into.Capture, from.Capture)
+	}
+
+	return into
+}
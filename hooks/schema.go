@@ -0,0 +1,102 @@
+package hooks_logic
+
+import (
+	"fmt"
+	"strings"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/schema_validator"
+)
+
+// RequestBodySchema returns the JSON request body schema of an operation.
+func RequestBodySchema(op operations.Operation) (oas_struct.Schema, bool) {
+	content := op.Details.RequestBody.Content
+	if json, ok := content["application/json"]; ok {
+		return json.Schema, true
+	}
+	for mediaType, c := range content {
+		if strings.HasSuffix(mediaType, "+json") {
+			return c.Schema, true
+		}
+	}
+	return oas_struct.Schema{}, false
+}
+
+// schemaCheck validates a hook value against the part of the body schema at
+// its path, so numbers, enums, formats, bounds, patterns and nulls are judged
+// by what the OAS declares rather than by the generated example.
+func schemaCheck(oas oas_struct.OAS, bodySchema oas_struct.Schema) valueCheck {
+	return func(path []keyToken, existing any, exists bool, newVal any) error {
+		schema, err := schemaAt(oas, bodySchema, path)
+		if err != nil {
+			return errors.NewInvalidHookValueError(formatKey(path), newVal, err.Error())
+		}
+
+		violations := schema_validator.Validate(schema, newVal, oas, formatKey(path))
+		if len(violations) == 0 {
+			return nil
+		}
+
+		var messages []string
+		for _, violation := range violations {
+			messages = append(messages, violation.String())
+		}
+		return errors.NewInvalidHookValueError(formatKey(path), newVal, strings.Join(messages, "; "))
+	}
+}
+
+// schemaAt walks the body schema along a hook key.
+func schemaAt(oas oas_struct.OAS, schema oas_struct.Schema, path []keyToken) (oas_struct.Schema, error) {
+	for i, token := range path {
+		var err error
+		if token.isIndex || token.isAppend {
+			schema, err = schema_validator.ItemSchema(oas, schema)
+		} else {
+			schema, err = schema_validator.PropertySchema(oas, schema, token.name)
+		}
+		if err != nil {
+			if i == 0 {
+				return oas_struct.Schema{}, err
+			}
+			return oas_struct.Schema{}, fmt.Errorf("at %s: %w", formatKey(path[:i]), err)
+		}
+	}
+	return schema, nil
+}
+
+// checkAgainstGeneratedValue is used when there is no schema. A value replacing
+// a field must have the same JSON type as the generated value, and new fields
+// can be anything.
+func checkAgainstGeneratedValue(path []keyToken, existing any, exists bool, newVal any) error {
+	if !exists {
+		return nil
+	}
+
+	expected, actual := jsonType(existing), jsonType(newVal)
+	if expected == "null" || expected == actual {
+		return nil
+	}
+	return errors.NewInvalidHookValueError(formatKey(path), newVal, fmt.Sprintf("type mismatch: expected %s, got %s", expected, actual))
+}
+
+// jsonType names the JSON type of values decoded from either JSON or YAML,
+// which disagree on how numbers are represented.
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, float32, int, int64, int32, uint64:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
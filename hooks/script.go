@@ -0,0 +1,23 @@
+package hooks_logic
+
+import (
+	"github.com/alexplayer15/parmesan/script"
+)
+
+// RequestScript compiles the hook's script, or returns nil when it has none. A
+// script that is the path of a .star file is read relative to the hooks file.
+func (h HookEntry) RequestScript() (*script.Script, error) {
+	if h.Script == "" {
+		return nil, nil
+	}
+	return script.Load(h.Script, h.baseDir, "hook script")
+}
+
+// ResponseScript compiles the script of the hook's response checks, or returns
+// nil when it has none.
+func (h HookEntry) ResponseScript() (*script.Script, error) {
+	if h.Response == nil || h.Response.Script == "" {
+		return nil, nil
+	}
+	return script.Load(h.Response.Script, h.baseDir, "response script")
+}
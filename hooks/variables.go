@@ -0,0 +1,108 @@
+package hooks_logic
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/variables"
+)
+
+// ResolveHookVariables returns a copy of the hook with the {{variable}}
+// placeholders in its values filled in, so the values can be checked against
+// the OAS as they will be sent. A body value that is a single placeholder for
+// a variable in typed that is not a string takes that variable's type, so
+// `age: "{{petAge}}"` sets a number when petAge was captured as one.
+func ResolveHookVariables(hook HookEntry, typed map[string]any, lookup variables.Lookup) (HookEntry, error) {
+	resolved := hook
+
+	if hook.Body != nil {
+		resolved.Body = make(map[string]any, len(hook.Body))
+		for key, value := range hook.Body {
+			resolved.Body[key] = variables.ResolveValue(value, typed, lookup)
+			if names := unresolvedNames(resolved.Body[key]); len(names) > 0 {
+				return hook, fmt.Errorf("hook %s, body field %s: %w", hook.Describe(), key, errors.NewUnresolvedVariableError("variable", names[0]))
+			}
+		}
+	}
+
+	sections := []struct {
+		name   string
+		values *map[string]string
+	}{
+		{"headers", &resolved.Headers},
+		{"query", &resolved.Query},
+		{"pathParams", &resolved.PathParams},
+		{"cookies", &resolved.Cookies},
+	}
+	for _, section := range sections {
+		if *section.values == nil {
+			continue
+		}
+		values := maps.Clone(*section.values)
+		for name, value := range values {
+			values[name] = variables.Substitute(value, lookup)
+			if names := variables.Unresolved(values[name]); len(names) > 0 {
+				return hook, fmt.Errorf("hook %s, %s.%s: %w", hook.Describe(), section.name, name, errors.NewUnresolvedVariableError("variable", names[0]))
+			}
+		}
+		*section.values = values
+	}
+
+	return resolved, nil
+}
+
+// unresolvedNames lists the placeholders left anywhere in a body value.
+func unresolvedNames(value any) []string {
+	var names []string
+	switch typed := value.(type) {
+	case string:
+		names = variables.Unresolved(typed)
+	case map[string]any:
+		for _, item := range typed {
+			names = append(names, unresolvedNames(item)...)
+		}
+	case []any:
+		for _, item := range typed {
+			names = append(names, unresolvedNames(item)...)
+		}
+	}
+	return names
+}
+
+// isDynamic reports whether a value calls a ${function()} or uses a
+// {{variable}}, so it is only known when sending. It reads placeholders the way
+// ResolveHookVariables does, so lint skips exactly the values send-request
+// fills in before checking them, and reports the ones it would send as written.
+func isDynamic(value any) bool {
+	switch typed := value.(type) {
+	case string:
+		return callsFunction(typed) || len(variables.Unresolved(typed)) > 0
+	case map[string]any:
+		for _, item := range typed {
+			if isDynamic(item) {
+				return true
+			}
+		}
+	case []any:
+		return slices.ContainsFunc(typed, isDynamic)
+	}
+	return false
+}
+
+// callsFunction reports whether text has a ${ that EvaluateHookFunctions would
+// evaluate, rather than a literal one written as $${.
+func callsFunction(text string) bool {
+	for rest := text; ; {
+		start := strings.Index(rest, "${")
+		if start == -1 {
+			return false
+		}
+		if start == 0 || rest[start-1] != '$' {
+			return true
+		}
+		rest = rest[start+2:]
+	}
+}
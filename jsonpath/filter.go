@@ -0,0 +1,305 @@
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Filters keep the fields or elements they hold for:
+//
+//	[?(@.type=='primary')]          compare with ==, !=, <, <=, > or >=
+//	[?(@.price > 10 && @.active)]   combine with &&, || and !, group with ( )
+//	[?(@.discount)]                 the field exists
+//	[?(@.max == $.limit)]           compare with a value elsewhere in the document
+//
+// Literals are quoted strings, numbers, true, false and null.
+
+type filterExpr struct {
+	op          string
+	left, right *filterExpr
+	lhs, rhs    operand
+}
+
+// operand is a literal or a path relative to the current value (@) or the
+// document root ($).
+type operand struct {
+	isPath   bool
+	relative bool
+	path     []segment
+	literal  any
+}
+
+var comparisonOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (f *filterExpr) holds(current any, root any) bool {
+	switch f.op {
+	case "||":
+		return f.left.holds(current, root) || f.right.holds(current, root)
+	case "&&":
+		return f.left.holds(current, root) && f.right.holds(current, root)
+	case "!":
+		return !f.left.holds(current, root)
+	case "exists":
+		return len(f.lhs.resolve(current, root)) > 0
+	}
+
+	left, leftOk := f.lhs.value(current, root)
+	right, rightOk := f.rhs.value(current, root)
+	if !leftOk || !rightOk {
+		return f.op == "!=" && leftOk != rightOk
+	}
+	return compare(left, right, f.op)
+}
+
+func (o operand) resolve(current any, root any) []node {
+	start := node{value: root, path: []string{}}
+	if o.relative {
+		start = node{value: current, path: []string{}}
+	}
+	return evaluate(o.path, start, root)
+}
+
+// value is the literal, or the single value the path selects.
+func (o operand) value(current any, root any) (any, bool) {
+	if !o.isPath {
+		return o.literal, true
+	}
+	nodes := o.resolve(current, root)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0].value, true
+}
+
+func compare(left any, right any, op string) bool {
+	leftNumber, leftIsNumber := left.(float64)
+	rightNumber, rightIsNumber := right.(float64)
+	if leftIsNumber && rightIsNumber {
+		switch op {
+		case "==":
+			return leftNumber == rightNumber
+		case "!=":
+			return leftNumber != rightNumber
+		case "<":
+			return leftNumber < rightNumber
+		case "<=":
+			return leftNumber <= rightNumber
+		case ">":
+			return leftNumber > rightNumber
+		case ">=":
+			return leftNumber >= rightNumber
+		}
+	}
+
+	leftText, leftIsText := left.(string)
+	rightText, rightIsText := right.(string)
+	if leftIsText && rightIsText {
+		switch op {
+		case "<":
+			return leftText < rightText
+		case "<=":
+			return leftText <= rightText
+		case ">":
+			return leftText > rightText
+		case ">=":
+			return leftText >= rightText
+		}
+	}
+
+	switch op {
+	case "==":
+		return reflect.DeepEqual(left, right)
+	case "!=":
+		return !reflect.DeepEqual(left, right)
+	}
+	return false
+}
+
+type filterParser struct {
+	text string
+	pos  int
+}
+
+func parseFilter(text string) (*filterExpr, error) {
+	p := &filterParser{text: text}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if p.pos != len(p.text) {
+		return nil, fmt.Errorf("unexpected %q", p.text[p.pos:])
+	}
+	return expr, nil
+}
+
+func (p *filterParser) parseOr() (*filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterExpr{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (*filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterExpr{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (*filterExpr, error) {
+	p.skipSpaces()
+
+	if strings.HasPrefix(p.text[p.pos:], "!") && !strings.HasPrefix(p.text[p.pos:], "!=") {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &filterExpr{op: "!", left: inner}, nil
+	}
+
+	if p.consume("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (*filterExpr, error) {
+	lhs, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range comparisonOps {
+		if p.consume(op) {
+			rhs, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return &filterExpr{op: op, lhs: lhs, rhs: rhs}, nil
+		}
+	}
+
+	if !lhs.isPath {
+		return nil, fmt.Errorf("a literal on its own is not a filter, compare it with a path such as @.name")
+	}
+	return &filterExpr{op: "exists", lhs: lhs}, nil
+}
+
+func (p *filterParser) parseOperand() (operand, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.text) {
+		return operand{}, fmt.Errorf("expected a path or a value")
+	}
+
+	rest := p.text[p.pos:]
+	switch c := rest[0]; {
+	case c == '@' || c == '$':
+		end := pathEnd(rest)
+		segments, err := parse("$" + rest[1:end])
+		if err != nil {
+			return operand{}, err
+		}
+		p.pos += end
+		return operand{isPath: true, relative: c == '@', path: segments}, nil
+
+	case c == '\'' || c == '"':
+		var value strings.Builder
+		for i := 1; i < len(rest); i++ {
+			switch {
+			case rest[i] == '\\' && i+1 < len(rest):
+				i++
+				value.WriteByte(rest[i])
+			case rest[i] == c:
+				p.pos += i + 1
+				return operand{literal: value.String()}, nil
+			default:
+				value.WriteByte(rest[i])
+			}
+		}
+		return operand{}, fmt.Errorf("unterminated string %s", rest)
+
+	case c == '-' || c >= '0' && c <= '9':
+		end := 1
+		for end < len(rest) && strings.IndexByte("0123456789.eE+-", rest[end]) != -1 {
+			end++
+		}
+		number, err := strconv.ParseFloat(rest[:end], 64)
+		if err != nil {
+			return operand{}, fmt.Errorf("invalid number %q", rest[:end])
+		}
+		p.pos += end
+		return operand{literal: number}, nil
+	}
+
+	for keyword, value := range map[string]any{"true": true, "false": false, "null": nil} {
+		if strings.HasPrefix(rest, keyword) {
+			p.pos += len(keyword)
+			return operand{literal: value}, nil
+		}
+	}
+
+	return operand{}, fmt.Errorf("unexpected %q, expected @, $, a quoted string, a number, true, false or null", rest)
+}
+
+// pathEnd finds where a path operand ends: at the first space, operator or
+// closing parenthesis outside brackets.
+func pathEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			end := closingBracket(s[i:])
+			if end < 0 {
+				return len(s)
+			}
+			i += end
+		case ' ', '=', '!', '<', '>', '&', '|', ')':
+			return i
+		}
+	}
+	return len(s)
+}
+
+func (p *filterParser) consume(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.text[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) skipSpaces() {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+}
@@ -0,0 +1,287 @@
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alexplayer15/parmesan/jsonpointer"
+)
+
+// The supported subset of JSONPath:
+//
+//	$                  the whole document
+//	.name ['name']     a field, quoted when the name has dots or spaces
+//	[2] [-1]           an array element, negative indices count from the end
+//	.* [*]             every field or element
+//	..name             name at any depth
+//	[?(@.type=='a')]   every field or element the filter holds for, see filter.go
+
+type selectorKind int
+
+const (
+	selectName selectorKind = iota
+	selectIndex
+	selectWildcard
+	selectFilter
+)
+
+type segment struct {
+	kind      selectorKind
+	name      string
+	index     int
+	filter    *filterExpr
+	recursive bool
+}
+
+// node is a value in the document and the reference tokens leading to it.
+type node struct {
+	value any
+	path  []string
+}
+
+// Query returns every value expr selects from a document decoded by
+// encoding/json, in document order. Selecting nothing is not an error.
+func Query(doc any, expr string) ([]any, error) {
+	nodes, err := query(doc, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []any
+	for _, n := range nodes {
+		values = append(values, n.value)
+	}
+	return values, nil
+}
+
+// QueryPointers returns the JSON Pointer of every value expr selects, in
+// document order, so callers can change the values in place.
+func QueryPointers(doc any, expr string) ([]string, error) {
+	nodes, err := query(doc, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var pointers []string
+	for _, n := range nodes {
+		pointers = append(pointers, jsonpointer.Format(n.path))
+	}
+	return pointers, nil
+}
+
+// IsPath reports whether expr looks like a JSONPath rather than a JSON Pointer
+// or a plain key.
+func IsPath(expr string) bool {
+	return strings.HasPrefix(expr, "$")
+}
+
+func query(doc any, expr string) ([]node, error) {
+	segments, err := parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return evaluate(segments, node{value: doc, path: []string{}}, doc), nil
+}
+
+func evaluate(segments []segment, start node, root any) []node {
+	nodes := []node{start}
+	for _, seg := range segments {
+		var next []node
+		for _, n := range nodes {
+			if seg.recursive {
+				for _, descendant := range descendants(n) {
+					next = append(next, seg.apply(descendant, root)...)
+				}
+				continue
+			}
+			next = append(next, seg.apply(n, root)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func parse(expr string) ([]segment, error) {
+	if !IsPath(expr) {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with $", expr)
+	}
+
+	var segments []segment
+	rest := expr[1:]
+
+	for rest != "" {
+		recursive := false
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			recursive = true
+			rest = rest[2:]
+		case rest[0] == '.':
+			rest = rest[1:]
+		case rest[0] == '[':
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q", expr, rest)
+		}
+
+		var seg segment
+		var err error
+		if strings.HasPrefix(rest, "[") {
+			seg, rest, err = parseBracket(rest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSONPath %q: %w", expr, err)
+			}
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty field name", expr)
+			}
+			seg = segment{kind: selectName, name: name}
+			if name == "*" {
+				seg = segment{kind: selectWildcard}
+			}
+		}
+
+		seg.recursive = recursive
+		segments = append(segments, seg)
+	}
+
+	return segments, nil
+}
+
+// parseBracket parses a [...] selector at the start of rest and returns what
+// follows it.
+func parseBracket(rest string) (segment, string, error) {
+	end := closingBracket(rest)
+	if end < 0 {
+		return segment{}, "", fmt.Errorf("missing ] in %q", rest)
+	}
+
+	inner := strings.TrimSpace(rest[1:end])
+	rest = rest[end+1:]
+
+	switch {
+	case inner == "*":
+		return segment{kind: selectWildcard}, rest, nil
+	case strings.HasPrefix(inner, "?"):
+		filter, err := parseFilter(inner[1:])
+		if err != nil {
+			return segment{}, "", fmt.Errorf("filter [%s]: %w", inner, err)
+		}
+		return segment{kind: selectFilter, filter: filter}, rest, nil
+	case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+		return segment{kind: selectName, name: inner[1 : len(inner)-1]}, rest, nil
+	}
+
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return segment{}, "", fmt.Errorf("unsupported selector [%s]", inner)
+	}
+
+	return segment{kind: selectIndex, index: index}, rest, nil
+}
+
+// closingBracket finds the ] matching the [ at the start of s, skipping any
+// inside quotes or nested brackets.
+func closingBracket(s string) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '\'' || s[i] == '"':
+			quote = s[i]
+		case s[i] == '[':
+			depth++
+		case s[i] == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (seg segment) apply(n node, root any) []node {
+	switch seg.kind {
+	case selectName:
+		if object, ok := n.value.(map[string]any); ok {
+			if value, ok := object[seg.name]; ok {
+				return []node{n.child(seg.name, value)}
+			}
+		}
+	case selectIndex:
+		if array, ok := n.value.([]any); ok {
+			index := seg.index
+			if index < 0 {
+				index += len(array)
+			}
+			if index >= 0 && index < len(array) {
+				return []node{n.child(strconv.Itoa(index), array[index])}
+			}
+		}
+	case selectWildcard:
+		return children(n)
+	case selectFilter:
+		var kept []node
+		for _, child := range children(n) {
+			if seg.filter.holds(child.value, root) {
+				kept = append(kept, child)
+			}
+		}
+		return kept
+	}
+	return nil
+}
+
+func (n node) child(token string, value any) node {
+	path := make([]string, len(n.path), len(n.path)+1)
+	copy(path, n.path)
+	return node{value: value, path: append(path, token)}
+}
+
+// children returns the fields of an object, sorted by name so results are
+// stable, or the elements of an array.
+func children(n node) []node {
+	switch typed := n.value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		nodes := make([]node, 0, len(keys))
+		for _, key := range keys {
+			nodes = append(nodes, n.child(key, typed[key]))
+		}
+		return nodes
+	case []any:
+		nodes := make([]node, 0, len(typed))
+		for i, item := range typed {
+			nodes = append(nodes, n.child(strconv.Itoa(i), item))
+		}
+		return nodes
+	}
+	return nil
+}
+
+// descendants returns n and everything nested inside it.
+func descendants(n node) []node {
+	all := []node{n}
+	for _, child := range children(n) {
+		all = append(all, descendants(child)...)
+	}
+	return all
+}
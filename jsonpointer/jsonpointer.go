@@ -0,0 +1,78 @@
+package jsonpointer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse splits an RFC 6901 JSON Pointer such as /items/0/a~1b into its
+// unescaped reference tokens. The empty pointer refers to the whole document.
+func Parse(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		//~1 must be replaced before ~0 so that ~01 becomes ~1 and not /
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// Get returns the value pointer refers to in a document decoded by encoding/json.
+func Get(doc any, pointer string) (any, error) {
+	tokens, err := Parse(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	current := doc
+	for i, token := range tokens {
+		switch typed := current.(type) {
+		case map[string]any:
+			value, ok := typed[token]
+			if !ok {
+				return nil, fmt.Errorf("JSON pointer %q matched nothing: no field %q at %s", pointer, token, Format(tokens[:i]))
+			}
+			current = value
+		case []any:
+			index, err := ArrayIndex(token, len(typed))
+			if err != nil {
+				return nil, fmt.Errorf("JSON pointer %q matched nothing at %s: %w", pointer, Format(tokens[:i]), err)
+			}
+			current = typed[index]
+		default:
+			return nil, fmt.Errorf("JSON pointer %q matched nothing: %s is not an object or array", pointer, Format(tokens[:i]))
+		}
+	}
+
+	return current, nil
+}
+
+// ArrayIndex converts a reference token into an index of an array of length n.
+func ArrayIndex(token string, n int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%q is not an array index", token)
+	}
+	if index >= n {
+		return 0, fmt.Errorf("index %d is out of range for an array of %d", index, n)
+	}
+	return index, nil
+}
+
+// Format builds a pointer from unescaped tokens.
+func Format(tokens []string) string {
+	var builder strings.Builder
+	for _, token := range tokens {
+		builder.WriteString("/")
+		builder.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return builder.String()
+}
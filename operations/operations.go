@@ -0,0 +1,160 @@
+package operations
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+)
+
+// PathParamPattern matches a {param} in an OAS path template.
+var PathParamPattern = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+type Operation struct {
+	Path    string
+	Method  string
+	Details oas_struct.Method
+}
+
+// Name identifies the operation in output, preferring the operationId from the spec.
+func (o Operation) Name() string {
+	if o.Details.OperationId != "" {
+		return o.Details.OperationId
+	}
+	return fmt.Sprintf("%s %s", o.Method, o.Path)
+}
+
+// Key identifies the operation by its method and path template.
+func (o Operation) Key() string {
+	return o.Method + " " + o.Path
+}
+
+// ListOperations returns every operation in the spec sorted by path then method so output is stable.
+func ListOperations(oas oas_struct.OAS) []Operation {
+	var ops []Operation
+	for path, methods := range oas.Paths {
+		for method, details := range methods {
+			ops = append(ops, Operation{
+				Path:    path,
+				Method:  strings.ToUpper(method),
+				Details: details,
+			})
+		}
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		return ops[i].Method < ops[j].Method
+	})
+
+	return ops
+}
+
+func FindByOperationId(oas oas_struct.OAS, operationId string) (Operation, bool) {
+	for _, op := range ListOperations(oas) {
+		if op.Details.OperationId == operationId {
+			return op, true
+		}
+	}
+	return Operation{}, false
+}
+
+// FindOperation maps a request back to the operation it was generated from.
+// The server's base path is stripped from the URL before matching path templates.
+func FindOperation(oas oas_struct.OAS, serverURL string, method string, rawURL string) (Operation, bool) {
+	requestPath, ok := pathWithinServer(serverURL, rawURL)
+	if !ok {
+		return Operation{}, false
+	}
+
+	//prefer a literal match over a templated one, e.g. /users/me over /users/{id}
+	var templated *Operation
+	for _, op := range ListOperations(oas) {
+		if op.Method != strings.ToUpper(method) {
+			continue
+		}
+		if op.Path == requestPath {
+			return op, true
+		}
+		if _, ok := MatchPathTemplate(op.Path, requestPath); ok && templated == nil {
+			matched := op
+			templated = &matched
+		}
+	}
+
+	if templated != nil {
+		return *templated, true
+	}
+	return Operation{}, false
+}
+
+// PathParams returns the values a request URL fills into the operation's path template.
+func (o Operation) PathParams(serverURL string, rawURL string) map[string]string {
+	requestPath, ok := pathWithinServer(serverURL, rawURL)
+	if !ok {
+		return map[string]string{}
+	}
+	params, ok := MatchPathTemplate(o.Path, requestPath)
+	if !ok {
+		return map[string]string{}
+	}
+	return params
+}
+
+// pathWithinServer strips the server's base path from the URL's path.
+func pathWithinServer(serverURL string, rawURL string) (string, bool) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+
+	requestPath := parsedURL.Path
+	if parsedServer, err := url.Parse(serverURL); err == nil {
+		requestPath, _ = TrimBasePath(requestPath, parsedServer.Path)
+	}
+	return requestPath, true
+}
+
+// TrimBasePath strips a server's base path, such as /api, from the start of a
+// request path and reports whether it did. Only whole segments are stripped,
+// so /apiary is not within /api.
+func TrimBasePath(path string, basePath string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, strings.TrimRight(basePath, "/"))
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+		return path, false
+	}
+	return rest, true
+}
+
+// MatchPathTemplate checks a concrete path against an OAS path template such as
+// /users/{id} and returns the values captured for each path parameter.
+func MatchPathTemplate(template string, path string) (map[string]string, bool) {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(templateSegments) != len(pathSegments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			value, err := url.PathUnescape(pathSegments[i])
+			if err != nil || value == "" {
+				return nil, false
+			}
+			params[strings.Trim(segment, "{}")] = value
+			continue
+		}
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
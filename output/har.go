@@ -0,0 +1,254 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/alexplayer15/parmesan/results"
+)
+
+// HAR 1.2 types. HAR counts the TLS handshake as part of connect, with ssl
+// repeating it on its own; see http://www.softwareishard.com/blog/har-12-spec/
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	Error       string         `json:"_error,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+const harHeader = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "parmesan", "version": "1.0"},
+    "entries": [`
+
+const harFooter = `
+    ]
+  }
+}
+`
+
+// harWriter streams entries into a HAR log so it can be imported into browser
+// devtools and proxies. The file is only valid JSON once Close has run.
+type harWriter struct {
+	file    *os.File
+	written int
+}
+
+func newHARWriter(path string) (*harWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	if _, err := file.WriteString(harHeader); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write output file: %w", err)
+	}
+	return &harWriter{file: file}, nil
+}
+
+func (w *harWriter) Write(r results.Result) error {
+	jsonData, err := json.MarshalIndent(newHAREntry(r), "      ", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal HAR entry: %w", err)
+	}
+
+	separator := ",\n      "
+	if w.written == 0 {
+		separator = "\n      "
+	}
+
+	if _, err := w.file.WriteString(separator + string(jsonData)); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	w.written++
+	return nil
+}
+
+func (w *harWriter) Close() error {
+	if _, err := w.file.WriteString(harFooter); err != nil {
+		w.file.Close()
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return w.file.Close()
+}
+
+func newHAREntry(r results.Result) harEntry {
+	timing := r.Response.Timing
+	httpVersion := r.Response.Proto
+	if httpVersion == "" {
+		httpVersion = "HTTP/1.1"
+	}
+
+	wait := timing.TTFB - timing.DNS - timing.Connect - timing.TLS
+	receive := timing.Total - timing.TTFB
+
+	entry := harEntry{
+		StartedDateTime: timing.Start.Format(time.RFC3339Nano),
+		Time:            milliseconds(timing.Total),
+		Request: harRequest{
+			Method:      r.Request.Method,
+			URL:         r.Request.Url,
+			HTTPVersion: httpVersion,
+			Cookies:     []harNameValue{},
+			Headers:     requestHeaders(r.Request.Headers),
+			QueryString: queryString(r.Request.Url),
+			HeadersSize: -1,
+			BodySize:    len(r.Request.Body),
+		},
+		Response: harResponse{
+			Status:      r.Response.StatusCode,
+			StatusText:  http.StatusText(r.Response.StatusCode),
+			HTTPVersion: httpVersion,
+			Cookies:     []harNameValue{},
+			Headers:     responseHeaders(r.Response.Headers),
+			Content: harContent{
+				Size:     len(r.Response.Body),
+				MimeType: mediaType(r.Response.Headers.Get("Content-Type")),
+				Text:     r.Response.Body,
+			},
+			RedirectURL: r.Response.Headers.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    r.Response.ContentLength,
+		},
+		Timings: harTimings{
+			Blocked: -1,
+			DNS:     optionalMilliseconds(timing.DNS),
+			Connect: optionalMilliseconds(timing.Connect + timing.TLS),
+			SSL:     optionalMilliseconds(timing.TLS),
+			Send:    0,
+			Wait:    milliseconds(max(wait, 0)),
+			Receive: milliseconds(max(receive, 0)),
+		},
+		Comment: r.Operation,
+	}
+
+	//a request that could not be sent has no response, only why it failed
+	if r.Err != nil {
+		entry.Response.Error = r.Err.Error()
+	}
+
+	if r.Request.Body != "" {
+		entry.Request.PostData = &harPostData{
+			MimeType: mediaType(r.Request.Headers["Content-Type"]),
+			Text:     r.Request.Body,
+		}
+	}
+
+	return entry
+}
+
+func requestHeaders(headers map[string]string) []harNameValue {
+	nameValues := []harNameValue{}
+	for name, value := range headers {
+		nameValues = append(nameValues, harNameValue{Name: name, Value: value})
+	}
+	sort.Slice(nameValues, func(i, j int) bool { return nameValues[i].Name < nameValues[j].Name })
+	return nameValues
+}
+
+func responseHeaders(headers http.Header) []harNameValue {
+	nameValues := []harNameValue{}
+	for name, values := range headers {
+		for _, value := range values {
+			nameValues = append(nameValues, harNameValue{Name: name, Value: value})
+		}
+	}
+	sort.Slice(nameValues, func(i, j int) bool { return nameValues[i].Name < nameValues[j].Name })
+	return nameValues
+}
+
+func queryString(rawURL string) []harNameValue {
+	nameValues := []harNameValue{}
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nameValues
+	}
+	for name, values := range parsedURL.Query() {
+		for _, value := range values {
+			nameValues = append(nameValues, harNameValue{Name: name, Value: value})
+		}
+	}
+	sort.Slice(nameValues, func(i, j int) bool { return nameValues[i].Name < nameValues[j].Name })
+	return nameValues
+}
+
+func mediaType(contentType string) string {
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return parsed
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// optionalMilliseconds reports phases that did not happen as -1, as HAR requires.
+func optionalMilliseconds(d time.Duration) float64 {
+	if d == 0 {
+		return -1
+	}
+	return milliseconds(d)
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/alexplayer15/parmesan/results"
+)
+
+// jsonArrayWriter writes the saved responses as one indented JSON array,
+// writing each element as soon as it arrives rather than holding them all.
+type jsonArrayWriter struct {
+	file    *os.File
+	written int
+}
+
+func newJSONArrayWriter(path string) (*jsonArrayWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	if _, err := file.WriteString("["); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write output file: %w", err)
+	}
+	return &jsonArrayWriter{file: file}, nil
+}
+
+func (w *jsonArrayWriter) Write(r results.Result) error {
+	jsonData, err := json.MarshalIndent(results.NewSavedResponse(r), "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	separator := ",\n  "
+	if w.written == 0 {
+		separator = "\n  "
+	}
+
+	if _, err := w.file.WriteString(separator + string(jsonData)); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	w.written++
+	return nil
+}
+
+func (w *jsonArrayWriter) Close() error {
+	closing := "\n]"
+	if w.written == 0 {
+		closing = "]"
+	}
+	if _, err := w.file.WriteString(closing); err != nil {
+		w.file.Close()
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return w.file.Close()
+}
+
+// ndjsonWriter writes one compact JSON record per line, each complete on its own.
+type ndjsonWriter struct {
+	file *os.File
+}
+
+func newNDJSONWriter(path string) (*ndjsonWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return &ndjsonWriter{file: file}, nil
+}
+
+func (w *ndjsonWriter) Write(r results.Result) error {
+	jsonData, err := json.Marshal(results.NewSavedResponse(r))
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	if _, err := w.file.Write(append(jsonData, '\n')); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/alexplayer15/parmesan/results"
+)
+
+const (
+	FormatJSON         = "json"
+	FormatNDJSON       = "ndjson"
+	FormatHAR          = "har"
+	FormatPerOperation = "per-operation"
+)
+
+// ResponseWriter saves results as they arrive, so a run that is interrupted
+// still leaves everything sent so far on disk.
+type ResponseWriter interface {
+	Write(r results.Result) error
+	Close() error
+}
+
+func ValidateFormats(formats []string) error {
+	for _, format := range formats {
+		switch format {
+		case FormatJSON, FormatNDJSON, FormatHAR, FormatPerOperation:
+		default:
+			return fmt.Errorf("unknown output format %q, use %s, %s, %s or %s", format, FormatJSON, FormatNDJSON, FormatHAR, FormatPerOperation)
+		}
+	}
+	return nil
+}
+
+// NewResponseWriter opens a writer for each format in outputDir, naming files after baseName.
+func NewResponseWriter(formats []string, outputDir string, baseName string) (ResponseWriter, error) {
+	writers := &multiWriter{}
+
+	for _, format := range formats {
+		var writer ResponseWriter
+		var err error
+
+		switch format {
+		case FormatJSON:
+			writer, err = newJSONArrayWriter(filepath.Join(outputDir, baseName+".json"))
+		case FormatNDJSON:
+			writer, err = newNDJSONWriter(filepath.Join(outputDir, baseName+".ndjson"))
+		case FormatHAR:
+			writer, err = newHARWriter(filepath.Join(outputDir, baseName+".har"))
+		case FormatPerOperation:
+			writer, err = newPerOperationWriter(filepath.Join(outputDir, baseName))
+		default:
+			err = fmt.Errorf("unknown output format %q", format)
+		}
+
+		if err != nil {
+			writers.Close()
+			return nil, err
+		}
+		writers.writers = append(writers.writers, writer)
+	}
+
+	return writers, nil
+}
+
+// multiWriter fans results out to every format. Close is safe to call more than
+// once so callers can both defer it and check its error on the happy path.
+type multiWriter struct {
+	writers []ResponseWriter
+	closed  bool
+}
+
+func (m *multiWriter) Write(r results.Result) error {
+	for _, writer := range m.writers {
+		if err := writer.Write(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *multiWriter) Close() error {
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+
+	var firstErr error
+	for _, writer := range m.writers {
+		if err := writer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/alexplayer15/parmesan/results"
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// perOperationWriter writes each response to its own file named after the operation.
+type perOperationWriter struct {
+	dir  string
+	seen map[string]int
+}
+
+func newPerOperationWriter(dir string) (*perOperationWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return &perOperationWriter{dir: dir, seen: map[string]int{}}, nil
+}
+
+func (w *perOperationWriter) Write(r results.Result) error {
+	name := operationFileName(r)
+
+	//an operation sent more than once gets numbered files instead of being overwritten
+	w.seen[name]++
+	if w.seen[name] > 1 {
+		name = fmt.Sprintf("%s-%d", name, w.seen[name])
+	}
+
+	jsonData, err := json.MarshalIndent(results.NewSavedResponse(r), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(w.dir, name+".json"), jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
+func (w *perOperationWriter) Close() error {
+	return nil
+}
+
+func operationFileName(r results.Result) string {
+	return unsafeFileNameChars.ReplaceAllString(r.Name(), "_")
+}
@@ -0,0 +1,310 @@
+package planner
+
+import (
+	"encoding/json"
+	"maps"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/jsonpointer"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/runtime_expression"
+)
+
+// Plan orders operations so that resources are created before they are read,
+// updated or deleted, and feeds the ids returned by creates into the paths of
+// the operations that need them.
+//
+// A path parameter such as {userId} in /users/{userId} is produced by the POST
+// on the collection before it, /users, from the field of its response that
+// matches the parameter name: userId, user_id, or else id.
+type Plan struct {
+	Steps []Step
+
+	oas       oas_struct.OAS
+	serverURL string
+	captured  map[string]map[string]any
+}
+
+// Step is a planned operation and where its path parameters come from.
+type Step struct {
+	Operation operations.Operation
+	Inputs    []Input
+}
+
+// Input is a path parameter filled from a field of another operation's response.
+type Input struct {
+	Param    string
+	Producer operations.Operation
+	Pointer  string
+}
+
+func New(oas oas_struct.OAS, serverURL string) *Plan {
+	plan := &Plan{oas: oas, serverURL: serverURL, captured: map[string]map[string]any{}}
+
+	ops := operations.ListOperations(oas)
+	creates := map[string]operations.Operation{}
+	for _, op := range ops {
+		if op.Method == "POST" {
+			creates[op.Path] = op
+		}
+	}
+
+	steps := make([]Step, 0, len(ops))
+	for _, op := range ops {
+		steps = append(steps, Step{Operation: op, Inputs: findInputs(oas, op, creates)})
+	}
+	plan.Steps = orderSteps(steps)
+
+	return plan
+}
+
+// CRUDPhase ranks methods so creates come first, then reads, then updates,
+// and deletes last.
+func CRUDPhase(method string) int {
+	switch strings.ToUpper(method) {
+	case "POST":
+		return 0
+	case "GET", "HEAD", "OPTIONS":
+		return 1
+	case "PUT":
+		return 2
+	case "PATCH":
+		return 3
+	case "DELETE":
+		return 4
+	}
+	return 1
+}
+
+// findInputs works out which create produces each path parameter of op.
+func findInputs(oas oas_struct.OAS, op operations.Operation, creates map[string]operations.Operation) []Input {
+	var inputs []Input
+
+	segments := strings.Split(op.Path, "/")
+	for i, segment := range segments {
+		match := operations.PathParamPattern.FindStringSubmatch(segment)
+		if match == nil {
+			continue
+		}
+
+		producer, ok := creates[strings.Join(segments[:i], "/")]
+		if !ok || producer.Path == op.Path {
+			continue
+		}
+
+		field, ok := matchResponseField(oas, producer, match[1])
+		if !ok {
+			continue
+		}
+
+		inputs = append(inputs, Input{Param: match[1], Producer: producer, Pointer: jsonpointer.Format([]string{field})})
+	}
+
+	return inputs
+}
+
+// matchResponseField finds the field of the producer's success response that
+// holds param. Without a response schema it assumes an id field.
+func matchResponseField(oas oas_struct.OAS, producer operations.Operation, param string) (string, bool) {
+	endsWithId := strings.HasSuffix(normalise(param), "id")
+
+	properties, ok := successResponseProperties(oas, producer)
+	if !ok {
+		return "id", endsWithId
+	}
+
+	if _, ok := properties[param]; ok {
+		return param, true
+	}
+	for _, name := range slices.Sorted(maps.Keys(properties)) {
+		if normalise(name) == normalise(param) {
+			return name, true
+		}
+	}
+	if _, ok := properties["id"]; ok && endsWithId {
+		return "id", true
+	}
+
+	return "", false
+}
+
+func successResponseProperties(oas oas_struct.OAS, op operations.Operation) (map[string]oas_struct.Property, bool) {
+	for _, status := range slices.Sorted(maps.Keys(op.Details.Responses)) {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+
+		response, err := oas.ResolveResponse(op.Details.Responses[status])
+		if err != nil {
+			continue
+		}
+		content, ok := response.Content["application/json"]
+		if !ok {
+			continue
+		}
+		schema, err := oas.ResolveSchema(content.Schema)
+		if err != nil || len(schema.Properties) == 0 {
+			continue
+		}
+		return schema.Properties, true
+	}
+
+	return nil, false
+}
+
+// normalise makes userId, user_id and user-id compare equal.
+func normalise(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+// orderSteps sorts steps by CRUD phase while keeping every producer ahead of
+// its consumers. Nested resources are created after their parents and deleted
+// before them.
+func orderSteps(steps []Step) []Step {
+	index := map[string]int{}
+	for i, step := range steps {
+		index[step.Operation.Key()] = i
+	}
+
+	incoming := make([]int, len(steps))
+	outgoing := make([][]int, len(steps))
+	for i, step := range steps {
+		for _, input := range step.Inputs {
+			producer := index[input.Producer.Key()]
+			outgoing[producer] = append(outgoing[producer], i)
+			incoming[i]++
+		}
+	}
+
+	var ready []int
+	for i := range steps {
+		if incoming[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	ordered := make([]Step, 0, len(steps))
+	for len(ready) > 0 {
+		sort.SliceStable(ready, func(a, b int) bool {
+			return before(steps[ready[a]].Operation, steps[ready[b]].Operation)
+		})
+		next := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, steps[next])
+
+		for _, consumer := range outgoing[next] {
+			incoming[consumer]--
+			if incoming[consumer] == 0 {
+				ready = append(ready, consumer)
+			}
+		}
+	}
+
+	return ordered
+}
+
+func before(a, b operations.Operation) bool {
+	if CRUDPhase(a.Method) != CRUDPhase(b.Method) {
+		return CRUDPhase(a.Method) < CRUDPhase(b.Method)
+	}
+
+	depthA, depthB := len(operations.PathParamPattern.FindAllString(a.Path, -1)), len(operations.PathParamPattern.FindAllString(b.Path, -1))
+	if depthA != depthB {
+		if a.Method == "DELETE" {
+			return depthA > depthB
+		}
+		return depthA < depthB
+	}
+
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	return a.Method < b.Method
+}
+
+// Order sorts requests into plan order. Requests that match no operation keep
+// their relative order at the end.
+func (p *Plan) Order(requests []request_sender.Request) []request_sender.Request {
+	position := map[string]int{}
+	for i, step := range p.Steps {
+		position[step.Operation.Key()] = i
+	}
+
+	rank := func(req request_sender.Request) int {
+		if op, ok := operations.FindOperation(p.oas, p.serverURL, req.Method, req.Url); ok {
+			return position[op.Key()]
+		}
+		return len(p.Steps)
+	}
+
+	ordered := slices.Clone(requests)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i]) < rank(ordered[j])
+	})
+
+	return ordered
+}
+
+// Fill replaces the path parameters of req that an earlier create produced.
+// Parameters with no captured value are left as their {param} placeholder.
+func (p *Plan) Fill(req request_sender.Request) request_sender.Request {
+	step, ok := p.step(req)
+	if !ok {
+		return req
+	}
+
+	for _, input := range step.Inputs {
+		value, ok := p.captured[input.Producer.Key()][input.Pointer]
+		if !ok {
+			continue
+		}
+		req.Url = strings.ReplaceAll(req.Url, "{"+input.Param+"}", url.PathEscape(runtime_expression.Stringify(value)))
+	}
+
+	return req
+}
+
+// Record captures the fields later operations need from a successful response.
+func (p *Plan) Record(req request_sender.Request, resp request_sender.Response) {
+	op, ok := operations.FindOperation(p.oas, p.serverURL, req.Method, req.Url)
+	if !ok || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return
+	}
+
+	var body any
+	if err := json.Unmarshal([]byte(resp.Body), &body); err != nil {
+		return
+	}
+
+	for _, step := range p.Steps {
+		for _, input := range step.Inputs {
+			if input.Producer.Key() != op.Key() {
+				continue
+			}
+			if value, err := jsonpointer.Get(body, input.Pointer); err == nil {
+				if p.captured[op.Key()] == nil {
+					p.captured[op.Key()] = map[string]any{}
+				}
+				p.captured[op.Key()][input.Pointer] = value
+			}
+		}
+	}
+}
+
+func (p *Plan) step(req request_sender.Request) (Step, bool) {
+	op, ok := operations.FindOperation(p.oas, p.serverURL, req.Method, req.Url)
+	if !ok {
+		return Step{}, false
+	}
+	for _, step := range p.Steps {
+		if step.Operation.Key() == op.Key() {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
@@ -0,0 +1,128 @@
+package reports
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexplayer15/parmesan/results"
+)
+
+type htmlReport struct {
+	Title   string
+	Summary *results.Summary
+	Results []htmlResult
+}
+
+type htmlResult struct {
+	Name            string
+	Passed          bool
+	Method          string
+	Url             string
+	RequestHeaders  []string
+	RequestBody     string
+	Status          string
+	ResponseHeaders []string
+	ResponseBody    string
+	Duration        string
+	Failures        []string
+	Violations      []string
+}
+
+// WriteHTML writes a self-contained HTML report, with all styling inlined so the
+// file can be opened or attached to a CI run on its own.
+func WriteHTML(w io.Writer, title string, runResults []results.Result, skipped int) error {
+	report := htmlReport{
+		Title:   title,
+		Summary: results.Summarise(runResults, skipped),
+	}
+
+	for _, r := range runResults {
+		report.Results = append(report.Results, htmlResult{
+			Name:            r.Name(),
+			Passed:          r.Passed(),
+			Method:          r.Request.Method,
+			Url:             r.Request.Url,
+			RequestHeaders:  formatHeaders(r.Request.Headers),
+			RequestBody:     r.Request.Body,
+			Status:          statusText(r),
+			ResponseHeaders: formatMultiHeaders(r.Response.Headers),
+			ResponseBody:    r.Response.Body,
+			Duration:        r.Response.Timing.Total.Round(time.Millisecond).String(),
+			Failures:        r.Failures,
+			Violations:      r.Violations,
+		})
+	}
+
+	if err := htmlTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
+
+func formatHeaders(headers map[string]string) []string {
+	var lines []string
+	for key, value := range headers {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, value))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func formatMultiHeaders(headers map[string][]string) []string {
+	var lines []string
+	for key, values := range headers {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, strings.Join(values, ", ")))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #222; }
+h1 { font-size: 1.5rem; }
+.summary span { display: inline-block; margin-right: 1.5rem; font-weight: bold; }
+.pass { color: #1a7f37; }
+.fail { color: #cf222e; }
+details { border: 1px solid #d0d7de; border-radius: 6px; margin: 0.5rem 0; padding: 0.5rem 1rem; }
+summary { cursor: pointer; font-family: monospace; font-size: 1rem; }
+pre { background: #f6f8fa; padding: 0.75rem; overflow-x: auto; white-space: pre-wrap; word-break: break-word; }
+h3 { font-size: 1rem; margin-bottom: 0.25rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="summary">
+<span>Sent: {{.Summary.Sent}}</span>
+<span class="pass">Succeeded: {{.Summary.Succeeded}}</span>
+<span class="fail">Failed: {{.Summary.Failed}}</span>
+<span>Skipped: {{.Summary.Skipped}}</span>
+</p>
+{{range .Results}}
+<details{{if not .Passed}} open{{end}}>
+<summary><span class="{{if .Passed}}pass{{else}}fail{{end}}">{{if .Passed}}PASS{{else}}FAIL{{end}}</span> {{.Name}} &mdash; {{.Status}} in {{.Duration}}</summary>
+{{if .Failures}}<h3>Failures</h3><ul>{{range .Failures}}<li class="fail">{{.}}</li>{{end}}</ul>{{end}}
+{{if .Violations}}<h3>Validation</h3><ul>{{range .Violations}}<li>{{.}}</li>{{end}}</ul>{{end}}
+<h3>Request</h3>
+<pre>{{.Method}} {{.Url}}
+{{range .RequestHeaders}}{{.}}
+{{end}}
+{{.RequestBody}}</pre>
+<h3>Response</h3>
+<pre>{{.Status}}
+{{range .ResponseHeaders}}{{.}}
+{{end}}
+{{.ResponseBody}}</pre>
+</details>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,124 @@
+package reports
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alexplayer15/parmesan/results"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     float64          `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      float64         `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+// WriteJUnit writes one testcase per sent request, with a failure element for each
+// result that failed the run criteria.
+func WriteJUnit(w io.Writer, suiteName string, runResults []results.Result, skipped int) error {
+	suite := junitTestSuite{
+		Name:    suiteName,
+		Skipped: skipped,
+	}
+
+	for _, r := range runResults {
+		seconds := r.Response.Timing.Total.Seconds()
+		testCase := junitTestCase{
+			Name:      r.Name(),
+			ClassName: suiteName,
+			Time:      seconds,
+			SystemOut: fmt.Sprintf("%s %s -> %s", r.Request.Method, r.Request.Url, statusText(r)),
+		}
+
+		if !r.Passed() {
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Message: strings.Join(r.Failures, "; "),
+				Type:    failureType(r),
+				Details: failureDetails(r),
+			}
+		}
+
+		suite.Tests++
+		suite.Time += seconds
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	report := junitTestSuites{
+		Name:     suiteName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	return nil
+}
+
+func failureType(r results.Result) string {
+	if r.Err != nil {
+		return "TransportError"
+	}
+	if len(r.Violations) > 0 {
+		return "SchemaViolation"
+	}
+	return "AssertionFailure"
+}
+
+func failureDetails(r results.Result) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s %s\n", r.Request.Method, r.Request.Url)
+	fmt.Fprintf(&builder, "Status: %s\n", statusText(r))
+	for _, failure := range r.Failures {
+		fmt.Fprintf(&builder, "Failure: %s\n", failure)
+	}
+	for _, violation := range r.Violations {
+		fmt.Fprintf(&builder, "Violation: %s\n", violation)
+	}
+	return builder.String()
+}
+
+func statusText(r results.Result) string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+	return fmt.Sprintf("%d", r.Response.StatusCode)
+}
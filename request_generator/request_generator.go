@@ -28,6 +28,41 @@ func GenerateHttpRequest(oas oas_struct.OAS, chosenServerIndex int) (string, err
 	return httpRequests.String(), nil
 }
 
+// GenerateRequestForOperation generates the request for a single operation, in
+// the same .http format as GenerateHttpRequest.
+func GenerateRequestForOperation(oas oas_struct.OAS, chosenServerIndex int, path string, method string) (string, error) {
+	serverURL := oas.Servers[chosenServerIndex].URL
+	if serverURL == "" {
+		return "", fmt.Errorf("server URL is empty")
+	}
+
+	for specMethod, methodData := range oas.Paths[path] {
+		if !strings.EqualFold(specMethod, method) {
+			continue
+		}
+
+		var httpRequest strings.Builder
+		if err := generateHttpRequestForMethod(&httpRequest, specMethod, methodData, joinURL(serverURL, path), oas); err != nil {
+			return "", fmt.Errorf("failed to generate HTTP request for %s %s: %w", method, path, err)
+		}
+		return httpRequest.String(), nil
+	}
+
+	return "", fmt.Errorf("no %s operation for path %s in the OAS", strings.ToUpper(method), path)
+}
+
+// GenerateRequestBody generates just the JSON request body of an operation,
+// or an empty string when it has none.
+func GenerateRequestBody(oas oas_struct.OAS, path string, method string) (string, error) {
+	for specMethod, methodData := range oas.Paths[path] {
+		if strings.EqualFold(specMethod, method) {
+			return handleRequestBody(methodData.RequestBody, oas, path, method)
+		}
+	}
+
+	return "", fmt.Errorf("no %s operation for path %s in the OAS", strings.ToUpper(method), path)
+}
+
 func generateRequestForPath(builder *strings.Builder, fullURL string, methods map[string]oas_struct.Method, oas oas_struct.OAS) error {
 	for method, methodData := range methods {
 		err := generateHttpRequestForMethod(builder, method, methodData, fullURL, oas)
@@ -92,21 +127,7 @@ func resolveSchema(schema oas_struct.Schema, oas oas_struct.OAS) (oas_struct.Sch
 	if schema.Ref == "" {
 		return schema, nil
 	}
-	return resolveRef(schema.Ref, oas)
-}
-
-func resolveRef(ref string, oas oas_struct.OAS) (oas_struct.Schema, error) {
-	const prefix = "#/components/schemas/"
-	if !strings.HasPrefix(ref, prefix) {
-		return oas_struct.Schema{}, fmt.Errorf("unsupported ref format: %s", ref)
-	}
-
-	name := strings.TrimPrefix(ref, prefix)
-	schema, ok := oas.Components.Schemas[name]
-	if !ok {
-		return oas_struct.Schema{}, fmt.Errorf("schema not found: %s", name)
-	}
-	return schema, nil
+	return oas.ResolveRef(schema.Ref)
 }
 
 func generateJsonFromSchema(schema oas_struct.Schema, oas oas_struct.OAS) (string, error) {
@@ -163,7 +184,7 @@ func expandAllOfSchema(schema oas_struct.Schema, oas oas_struct.OAS) (oas_struct
 	for _, item := range schema.AllOf {
 		var props map[string]oas_struct.Property
 		if item.Ref != "" {
-			resolved, err := resolveRef(item.Ref, oas)
+			resolved, err := oas.ResolveRef(item.Ref)
 			if err != nil {
 				return combined, err
 			}
@@ -223,13 +244,13 @@ func generateJsonFromProperty(propName string, prop oas_struct.Property, oas oas
 
 func resolveProperty(prop oas_struct.Property, oas oas_struct.OAS) (oas_struct.Schema, error) {
 	if prop.Ref != "" {
-		return resolveRef(prop.Ref, oas)
+		return oas.ResolveRef(prop.Ref)
 	}
 
 	if len(prop.OneOf) > 0 {
 		selected := prop.OneOf[0]
 		if selected.Ref != "" {
-			return resolveRef(selected.Ref, oas)
+			return oas.ResolveRef(selected.Ref)
 		}
 		return selected, nil
 	}
@@ -237,7 +258,7 @@ func resolveProperty(prop oas_struct.Property, oas oas_struct.OAS) (oas_struct.S
 	if len(prop.AnyOf) > 0 {
 		selected := prop.AnyOf[0]
 		if selected.Ref != "" {
-			return resolveRef(selected.Ref, oas)
+			return oas.ResolveRef(selected.Ref)
 		}
 		return selected, nil
 	}
@@ -270,7 +291,7 @@ func expandAllOfProperty(prop oas_struct.Property, oas oas_struct.OAS) (oas_stru
 
 	for _, item := range prop.AllOf {
 		if item.Ref != "" {
-			resolved, err := resolveRef(item.Ref, oas)
+			resolved, err := oas.ResolveRef(item.Ref)
 			if err != nil {
 				return combined, err
 			}
@@ -0,0 +1,309 @@
+package request_sender
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/variables"
+)
+
+// The parser understands the .http format shared by the JetBrains HTTP Client
+// and the VS Code REST Client:
+//
+//	@host = http://localhost:8080
+//
+//	### Create a user
+//	# @name createUser
+//	POST {{host}}/users
+//	    ?notify=true
+//	Content-Type: application/json
+//
+//	< ./new-user.json
+//
+// Requests are separated by lines starting with ###. Comments start with # or
+// //, file variables are declared with @name = value and used as {{name}}.
+
+type parseState int
+
+const (
+	statePreamble parseState = iota
+	stateQuery
+	stateHeaders
+	stateBody
+	stateHandler
+)
+
+var (
+	fileVariablePattern = regexp.MustCompile(`^@([A-Za-z_][\w.-]*)\s*=\s*(.*)$`)
+	nameTagPattern      = regexp.MustCompile(`^(?:#|//)\s*@name\s+(\S+)`)
+	httpVersionPattern  = regexp.MustCompile(`^HTTP/\d(\.\d)?$`)
+)
+
+type httpLine struct {
+	number int
+	text   string
+}
+
+type rawRequest struct {
+	name        string
+	requestLine httpLine
+	query       []httpLine
+	headers     []httpLine
+	body        []httpLine
+}
+
+// ParseHttpRequestFile parses the requests in an .http file. Body includes such
+// as `< ./body.json` are resolved relative to the working directory; use
+// ParseHttpFile to resolve them relative to the file itself.
+func ParseHttpRequestFile(httpRequestFile string) ([]Request, error) {
+	return parseHttpRequests(httpRequestFile, ".")
+}
+
+// ParseHttpFile reads and parses the .http file at path.
+func ParseHttpFile(path string) ([]Request, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return []Request{}, fmt.Errorf("failed to read HTTP file: %w", err)
+	}
+
+	return parseHttpRequests(string(content), filepath.Dir(path))
+}
+
+func parseHttpRequests(httpRequestFile string, baseDir string) ([]Request, error) {
+	if strings.TrimSpace(httpRequestFile) == "" {
+		return []Request{}, errors.ErrEmptyHTTPFile
+	}
+
+	rawRequests, fileVariables := splitHttpFile(httpRequestFile)
+	if len(rawRequests) == 0 {
+		return []Request{}, errors.ErrEmptyHTTPFile
+	}
+
+	lookup := variables.FromMap(variables.Resolve(fileVariables))
+
+	var requests []Request
+	for _, raw := range rawRequests {
+		req, err := buildRequest(raw, lookup, baseDir)
+		if err != nil {
+			return []Request{}, fmt.Errorf("failed to extract req from block: %w", err)
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, nil
+}
+
+// splitHttpFile walks the file line by line, grouping lines into requests and
+// collecting file variables. File variables apply to the whole file no matter
+// where they are declared, as in both editor clients.
+func splitHttpFile(httpRequestFile string) ([]rawRequest, map[string]string) {
+	var requests []rawRequest
+	fileVariables := make(map[string]string)
+
+	current := rawRequest{}
+	state := statePreamble
+
+	flush := func() {
+		if state != statePreamble {
+			requests = append(requests, current)
+		}
+	}
+
+	for i, text := range strings.Split(httpRequestFile, "\n") {
+		line := httpLine{number: i + 1, text: strings.TrimRight(text, "\r")}
+		trimmed := strings.TrimSpace(line.text)
+
+		if strings.HasPrefix(trimmed, "###") {
+			flush()
+			current = rawRequest{name: strings.TrimSpace(strings.TrimLeft(trimmed, "#"))}
+			state = statePreamble
+			continue
+		}
+
+		if state == statePreamble {
+			if trimmed == "" {
+				continue
+			}
+			if match := nameTagPattern.FindStringSubmatch(trimmed); match != nil {
+				current.name = match[1]
+				continue
+			}
+			if isComment(trimmed) {
+				continue
+			}
+			if match := fileVariablePattern.FindStringSubmatch(trimmed); match != nil {
+				fileVariables[match[1]] = strings.TrimSpace(match[2])
+				continue
+			}
+			current.requestLine = line
+			state = stateQuery
+			continue
+		}
+
+		//indented lines starting with ? or & continue the URL of the request line
+		if state == stateQuery {
+			if trimmed != "" && trimmed != line.text && strings.ContainsAny(trimmed[:1], "?&") {
+				current.query = append(current.query, line)
+				continue
+			}
+			state = stateHeaders
+		}
+
+		if state == stateHeaders {
+			if trimmed == "" {
+				state = stateBody
+				continue
+			}
+			if isComment(trimmed) {
+				continue
+			}
+			current.headers = append(current.headers, line)
+			continue
+		}
+
+		//response handlers and references end the body, parmesan does not run them
+		if strings.HasPrefix(trimmed, "> ") || strings.HasPrefix(trimmed, ">> ") || strings.HasPrefix(trimmed, "<> ") {
+			state = stateHandler
+		}
+		if state == stateBody {
+			current.body = append(current.body, line)
+		}
+	}
+	flush()
+
+	return requests, fileVariables
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//")
+}
+
+func buildRequest(raw rawRequest, lookup variables.Lookup, baseDir string) (Request, error) {
+	method, url, err := parseRequestLine(raw, lookup)
+	if err != nil {
+		return Request{}, err
+	}
+
+	headers, err := parseHeaders(raw.headers, lookup)
+	if err != nil {
+		return Request{}, err
+	}
+
+	body, err := parseBody(raw.body, lookup, baseDir)
+	if err != nil {
+		return Request{}, err
+	}
+
+	return Request{
+		Name:    raw.name,
+		Method:  method,
+		Url:     url,
+		Headers: headers,
+		Body:    body,
+	}, nil
+}
+
+// parseRequestLine accepts `METHOD URL [HTTP/version]` or a bare URL, which
+// both editor clients send as a GET.
+func parseRequestLine(raw rawRequest, lookup variables.Lookup) (string, string, error) {
+	line := raw.requestLine
+	parts := strings.Fields(variables.Substitute(line.text, lookup))
+
+	if len(parts) == 3 && httpVersionPattern.MatchString(parts[2]) {
+		parts = parts[:2]
+	}
+
+	var method, url string
+	switch len(parts) {
+	case 1:
+		method, url = "GET", parts[0]
+	case 2:
+		method, url = parts[0], parts[1]
+	default:
+		return "", "", errors.NewParseError(line.number, fmt.Errorf("invalid request line: %q", strings.TrimSpace(line.text)))
+	}
+
+	if err := ValidateHTTPMethod(method); err != nil {
+		return "", "", errors.NewParseError(line.number, fmt.Errorf("failed to extract method: %w", err))
+	}
+
+	for _, query := range raw.query {
+		url += variables.Substitute(strings.TrimSpace(query.text), lookup)
+	}
+
+	//URLs still holding placeholders are completed from an environment at send time
+	if len(variables.Unresolved(url)) == 0 {
+		if err := validateURL(url); err != nil {
+			return "", "", errors.NewParseError(line.number, fmt.Errorf("failed to extract URL: %w", err))
+		}
+	}
+
+	return strings.ToUpper(method), url, nil
+}
+
+func parseHeaders(lines []httpLine, lookup variables.Lookup) (map[string]string, error) {
+	headers := make(map[string]string)
+
+	for _, line := range lines {
+		parts := strings.SplitN(line.text, ":", 2)
+		if len(parts) < 2 {
+			return map[string]string{}, errors.NewParseError(line.number, fmt.Errorf("header is in invalid format %q", strings.TrimSpace(line.text)))
+		}
+
+		key := variables.Substitute(strings.TrimSpace(parts[0]), lookup)
+		value := variables.Substitute(strings.TrimSpace(parts[1]), lookup)
+
+		headers[key] = value
+	}
+
+	return headers, nil
+}
+
+// parseBody keeps body lines exactly as written apart from trailing blank
+// lines. A line of the form `< ./file` is replaced by the file's contents and
+// `<@ ./file` does the same with {{var}} placeholders in the file substituted.
+func parseBody(lines []httpLine, lookup variables.Lookup, baseDir string) (string, error) {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1].text) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var bodyParts []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line.text)
+
+		if strings.HasPrefix(trimmed, "<@ ") || strings.HasPrefix(trimmed, "< ") {
+			substitute := strings.HasPrefix(trimmed, "<@")
+			includePath := strings.TrimSpace(strings.TrimLeft(trimmed, "<@"))
+			content, err := readBodyInclude(variables.Substitute(includePath, lookup), baseDir)
+			if err != nil {
+				return "", errors.NewParseError(line.number, err)
+			}
+			if substitute {
+				content = variables.Substitute(content, lookup)
+			}
+			bodyParts = append(bodyParts, content)
+			continue
+		}
+
+		bodyParts = append(bodyParts, variables.Substitute(line.text, lookup))
+	}
+
+	return strings.Join(bodyParts, "\n"), nil
+}
+
+func readBodyInclude(includePath string, baseDir string) (string, error) {
+	if !filepath.IsAbs(includePath) {
+		includePath = filepath.Join(baseDir, includePath)
+	}
+
+	content, err := os.ReadFile(includePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read body file %q: %w", includePath, err)
+	}
+
+	return strings.TrimSuffix(string(content), "\n"), nil
+}
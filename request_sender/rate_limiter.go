@@ -0,0 +1,118 @@
+package request_sender
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type RateLimitOptions struct {
+	RequestsPerSecond float64
+	Burst             int
+	Delay             time.Duration
+	PerHost           bool
+}
+
+// RateLimiter paces outgoing requests with a token bucket and an optional fixed
+// delay between requests. It is safe for concurrent use, callers reserve their
+// slot under the lock and then sleep outside of it.
+type RateLimiter struct {
+	opts    RateLimitOptions
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens      float64
+	last        time.Time
+	nextAllowed time.Time
+}
+
+func NewRateLimiter(opts RateLimitOptions) (*RateLimiter, error) {
+	if opts.RequestsPerSecond < 0 {
+		return nil, fmt.Errorf("rate must not be negative, got %v", opts.RequestsPerSecond)
+	}
+	if opts.Burst < 1 {
+		return nil, fmt.Errorf("burst must be at least 1, got %d", opts.Burst)
+	}
+	if opts.Delay < 0 {
+		return nil, fmt.Errorf("delay must not be negative, got %s", opts.Delay)
+	}
+
+	return &RateLimiter{
+		opts:    opts,
+		buckets: make(map[string]*bucket),
+	}, nil
+}
+
+// Wait blocks until the request to rawURL is allowed to go out, or returns the
+// context's error if it is cancelled first. A nil limiter never blocks.
+func (l *RateLimiter) Wait(ctx context.Context, rawURL string) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	wait := l.reserve(l.bucketKey(rawURL), time.Now())
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *RateLimiter) bucketKey(rawURL string) string {
+	if !l.opts.PerHost {
+		return ""
+	}
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsedURL.Host
+}
+
+func (l *RateLimiter) reserve(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.opts.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	var wait time.Duration
+
+	if l.opts.RequestsPerSecond > 0 {
+		elapsed := now.Sub(b.last).Seconds()
+		b.tokens = min(float64(l.opts.Burst), b.tokens+elapsed*l.opts.RequestsPerSecond)
+		b.last = now
+
+		//tokens can go negative, the debt is what later callers have to wait off
+		b.tokens--
+		if b.tokens < 0 {
+			wait = time.Duration(-b.tokens / l.opts.RequestsPerSecond * float64(time.Second))
+		}
+	}
+
+	if l.opts.Delay > 0 {
+		start := now.Add(wait)
+		if start.Before(b.nextAllowed) {
+			start = b.nextAllowed
+			wait = start.Sub(now)
+		}
+		b.nextAllowed = start.Add(l.opts.Delay)
+	}
+
+	return wait
+}
@@ -2,9 +2,11 @@ package request_sender
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"slices"
 	"strings"
@@ -13,159 +15,25 @@ import (
 )
 
 type Request struct {
+	Name    string
 	Method  string
 	Url     string
 	Headers map[string]string
 	Body    string
 }
 
-func ParseHttpRequestFile(httpRequestFile string) ([]Request, error) {
-	var requests []Request
-
-	if len(httpRequestFile) == 0 {
-		return []Request{}, errors.ErrEmptyHTTPFile
-	}
-
-	lines := strings.Split(httpRequestFile, "\n")
-
-	var blocks [][]string
-	var currentBlock []string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		//check if its the start of a new request
-		if strings.HasPrefix(line, "####") {
-			//to avoid appending empty block on first request
-			if len(currentBlock) > 0 {
-				blocks = append(blocks, currentBlock)
-			}
-			currentBlock = []string{}
-			continue
-		}
-
-		//append empty lines to be able to differentiate between headers and body later
-		currentBlock = append(currentBlock, line)
-	}
-
-	if len(currentBlock) > 0 {
-		blocks = append(blocks, currentBlock)
-	}
-
-	for _, block := range blocks {
-		if len(block) == 0 {
-			return []Request{}, fmt.Errorf("empty request block")
-		}
-		req, err := getRequestInfo(block)
-		if err != nil {
-			return []Request{}, fmt.Errorf("failed to extract req from block: %w", err)
-		}
-		requests = append(requests, req)
-	}
-
-	return requests, nil
-}
-
-func getRequestInfo(block []string) (Request, error) {
-	var request Request
-	method, err := getMethod(block)
-	if err != nil {
-		return Request{}, fmt.Errorf("failed to extract method: %w", err)
-	}
-	url, err := getURL(block)
-	if err != nil {
-		return Request{}, fmt.Errorf("failed to extract URL: %w", err)
-	}
-	headers, bodyStartingIndex, err := getHeaders(block)
-	if err != nil {
-		return Request{}, fmt.Errorf("failed to extract headers: %w", err)
-	}
-	body, err := getBody(block, bodyStartingIndex)
-	if err != nil {
-		return Request{}, fmt.Errorf("failed to extract body: %w", err)
-	}
-	request.Method = method
-	request.Url = url
-	request.Headers = headers
-	request.Body = body
-
-	return request, nil
-}
-
-func getMethod(block []string) (string, error) {
-	firstLine := block[0]
-	parts := strings.SplitN(firstLine, " ", 2)
-	if len(parts) < 2 {
-		return "", fmt.Errorf("invalid request line: %q", firstLine)
-	}
-
-	method := parts[0]
-	if err := ValidateHTTPMethod(method); err != nil {
-		return "", err
-	}
-
-	return method, nil
-}
-
-func getURL(block []string) (string, error) {
-	firstLine := block[0]
-	parts := strings.SplitN(firstLine, " ", 2)
-	if len(parts) < 2 {
-		return "", fmt.Errorf("invalid request line: %q", firstLine)
-	}
-
-	url := parts[1]
-	if err := validateURL(url); err != nil {
-		return "", err
-	}
-
-	return url, nil
-}
-
-func getHeaders(block []string) (map[string]string, int, error) {
-	headers := make(map[string]string)
-
-	for i := 1; i < len(block); i++ {
-		line := block[i]
-
-		if line == "" {
-			return headers, i, nil
-		}
-
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) < 2 {
-			return map[string]string{}, 0, fmt.Errorf("header is in invalid format %q", line)
-		}
-
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		headers[key] = value
-	}
-
-	return headers, len(block), nil
-}
-
-func getBody(block []string, bodyStartingIndex int) (string, error) {
-	if bodyStartingIndex >= len(block) {
-		return "", nil
-	}
-
-	var bodyLines []string
-
-	for i := bodyStartingIndex; i < len(block); i++ {
-		line := block[i]
-		bodyLines = append(bodyLines, line)
-	}
-
-	body := strings.Join(bodyLines, "\n")
-
-	return body, nil
+type Response struct {
+	StatusCode    int
+	Headers       http.Header
+	Body          string
+	Proto         string
+	ContentLength int64
+	Timing        Timing
 }
 
 func ValidateHTTPMethod(httpMethod string) error {
 	upperHTTPMethod := strings.ToUpper(httpMethod)
-	allowedMethods := []string{"GET", "POST", "PUT", "PATCH", "DELETE", "*"}
+	allowedMethods := []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "*"}
 
 	if slices.Contains(allowedMethods, upperHTTPMethod) {
 		return nil
@@ -202,27 +70,44 @@ func validateURL(rawURL string) error {
 	return nil
 }
 
-func SendHTTPRequest(req Request) (string, int, error) {
-	request, err := http.NewRequest(req.Method, req.Url, bytes.NewBuffer([]byte(req.Body)))
+// SendHTTPRequest sends req and reads the whole response. Cancelling ctx aborts
+// the request, including one that is already in flight.
+func SendHTTPRequest(ctx context.Context, client *http.Client, req Request) (Response, error) {
+	request, err := http.NewRequestWithContext(ctx, req.Method, req.Url, bytes.NewBuffer([]byte(req.Body)))
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to create HTTP request: %w", err)
+		return Response{}, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	for key, value := range req.Headers {
 		request.Header.Add(key, value)
 	}
 
-	client := &http.Client{}
+	recorder := newTimingRecorder()
+	request = request.WithContext(httptrace.WithClientTrace(request.Context(), recorder.trace()))
+
 	response, err := client.Do(request)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to send HTTP request: %w", err)
+		return Response{Timing: recorder.finish()}, fmt.Errorf("failed to send HTTP request: %w", err)
 	}
 	defer response.Body.Close()
 
 	responseBody, err := io.ReadAll(response.Body)
 	if err != nil {
-		return "", response.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+		return Response{StatusCode: response.StatusCode, Headers: response.Header, Proto: response.Proto, Timing: recorder.finish()}, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	//servers that stream or compress responses do not always send a Content-Length
+	contentLength := response.ContentLength
+	if contentLength < 0 {
+		contentLength = int64(len(responseBody))
 	}
 
-	return string(responseBody), response.StatusCode, nil
+	return Response{
+		StatusCode:    response.StatusCode,
+		Headers:       response.Header,
+		Body:          string(responseBody),
+		Proto:         response.Proto,
+		ContentLength: contentLength,
+		Timing:        recorder.finish(),
+	}, nil
 }
@@ -0,0 +1,74 @@
+package request_sender
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing breaks down where the time of a request went. Phases that did not
+// happen, such as DNS on a reused connection, are left at zero.
+type Timing struct {
+	Start   time.Time
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	TTFB    time.Duration
+	Total   time.Duration
+}
+
+// timingRecorder collects httptrace callbacks, which can fire from other
+// goroutines when the transport dials several addresses at once.
+type timingRecorder struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timing       Timing
+}
+
+func newTimingRecorder() *timingRecorder {
+	return &timingRecorder{start: time.Now()}
+}
+
+func (r *timingRecorder) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			r.record(func() { r.dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			r.record(func() { r.timing.DNS = time.Since(r.dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			r.record(func() { r.connectStart = time.Now() })
+		},
+		ConnectDone: func(string, string, error) {
+			r.record(func() { r.timing.Connect = time.Since(r.connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			r.record(func() { r.tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.record(func() { r.timing.TLS = time.Since(r.tlsStart) })
+		},
+		GotFirstResponseByte: func() {
+			r.record(func() { r.timing.TTFB = time.Since(r.start) })
+		},
+	}
+}
+
+func (r *timingRecorder) record(update func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	update()
+}
+
+func (r *timingRecorder) finish() Timing {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timing.Start = r.start
+	r.timing.Total = time.Since(r.start)
+	return r.timing
+}
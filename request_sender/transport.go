@@ -0,0 +1,125 @@
+package request_sender
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/alexplayer15/parmesan/errors"
+)
+
+type TransportOptions struct {
+	CACertFile     string
+	ClientCertFile string
+	ClientKeyFile  string
+	Insecure       bool
+	MinTLSVersion  string
+	ServerName     string
+	ProxyURL       string
+}
+
+func NewHTTPClient(opts TransportOptions) (*http.Client, error) {
+	tlsConfig, err := buildTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := buildProxyFunc(opts.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxy
+
+	return &http.Client{Transport: transport}, nil
+}
+
+func buildTLSConfig(opts TransportOptions) (*tls.Config, error) {
+	minVersion, err := parseTLSVersion(opts.MinTLSVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         minVersion,
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.Insecure,
+	}
+
+	if opts.CACertFile != "" {
+		pool, err := loadCACertPool(opts.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if opts.ClientCertFile != "" || opts.ClientKeyFile != "" {
+		if opts.ClientCertFile == "" || opts.ClientKeyFile == "" {
+			return nil, fmt.Errorf("both a client certificate and a client key must be provided for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertFile, opts.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func loadCACertPool(caCertFile string) (*x509.CertPool, error) {
+	caCert, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle %s: %w", caCertFile, err)
+	}
+
+	//start from the system pool so a private CA does not break requests to public hosts
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no valid PEM certificates found in CA bundle %s", caCertFile)
+	}
+
+	return pool, nil
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	switch strings.TrimSpace(version) {
+	case "":
+		return tls.VersionTLS12, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, errors.NewInvalidTLSVersionError("tls-min-version", version)
+	}
+}
+
+func buildProxyFunc(proxyURL string) (func(*http.Request) (*url.URL, error), error) {
+	//fall back to HTTP_PROXY, HTTPS_PROXY and NO_PROXY when no proxy is given explicitly
+	if proxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	parsedURL, err := url.Parse(proxyURL)
+	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return nil, errors.NewInvalidProxyURLError("proxy", proxyURL)
+	}
+
+	return http.ProxyURL(parsedURL), nil
+}
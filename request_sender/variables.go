@@ -0,0 +1,40 @@
+package request_sender
+
+import (
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/variables"
+)
+
+// ResolveVariables substitutes {{var}} placeholders left in req after parsing,
+// typically environment and dynamic variables, and validates the final URL.
+// A placeholder that lookup cannot fill is an error rather than being sent.
+func ResolveVariables(req Request, lookup variables.Lookup) (Request, error) {
+	resolved := req
+	resolved.Url = variables.Substitute(req.Url, lookup)
+	resolved.Body = variables.Substitute(req.Body, lookup)
+
+	resolved.Headers = make(map[string]string, len(req.Headers))
+	for key, value := range req.Headers {
+		resolved.Headers[variables.Substitute(key, lookup)] = variables.Substitute(value, lookup)
+	}
+
+	for _, part := range append([]string{resolved.Url, resolved.Body}, headerText(resolved.Headers)...) {
+		if names := variables.Unresolved(part); len(names) > 0 {
+			return Request{}, errors.NewUnresolvedVariableError("variable", names[0])
+		}
+	}
+
+	if err := validateURL(resolved.Url); err != nil {
+		return Request{}, err
+	}
+
+	return resolved, nil
+}
+
+func headerText(headers map[string]string) []string {
+	var text []string
+	for key, value := range headers {
+		text = append(text, key, value)
+	}
+	return text
+}
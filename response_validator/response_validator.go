@@ -0,0 +1,172 @@
+package response_validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/schema_validator"
+)
+
+// ValidateResponse checks a received response against what the operation declares:
+// the status code, the response headers, the Content-Type and the JSON body schema.
+func ValidateResponse(oas oas_struct.OAS, op operations.Operation, resp request_sender.Response) []schema_validator.Violation {
+	declared, ok := findDeclaredResponse(op.Details.Responses, resp.StatusCode)
+	if !ok {
+		return []schema_validator.Violation{{Path: "status", Msg: fmt.Sprintf("status code %d is not declared for this operation", resp.StatusCode)}}
+	}
+
+	declared, err := oas.ResolveResponse(declared)
+	if err != nil {
+		return []schema_validator.Violation{{Path: "status", Msg: err.Error()}}
+	}
+
+	violations := validateHeaders(oas, declared.Headers, resp)
+	violations = append(violations, validateContent(oas, declared.Content, resp)...)
+
+	return violations
+}
+
+func findDeclaredResponse(responses map[string]oas_struct.Response, statusCode int) (oas_struct.Response, bool) {
+	status := strconv.Itoa(statusCode)
+	if response, ok := responses[status]; ok {
+		return response, true
+	}
+
+	//OAS allows ranges such as 2XX, matched case-insensitively
+	for key, response := range responses {
+		if len(key) == 3 && strings.EqualFold(key[1:], "XX") && key[0] == status[0] {
+			return response, true
+		}
+	}
+
+	response, ok := responses["default"]
+	return response, ok
+}
+
+func validateHeaders(oas oas_struct.OAS, headers map[string]oas_struct.Header, resp request_sender.Response) []schema_validator.Violation {
+	var violations []schema_validator.Violation
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		//OAS says a Content-Type response header definition is ignored
+		if strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+
+		header := headers[name]
+		path := "header " + name
+		value := resp.Headers.Get(name)
+
+		if value == "" {
+			if header.Required {
+				violations = append(violations, schema_validator.Violation{Path: path, Msg: "required response header is missing"})
+			}
+			continue
+		}
+
+		schema, err := oas.ResolveSchema(header.Schema)
+		if err != nil {
+			violations = append(violations, schema_validator.Violation{Path: path, Msg: err.Error()})
+			continue
+		}
+		violations = append(violations, schema_validator.Validate(schema, coerceHeaderValue(value, schema.Type), oas, path)...)
+	}
+
+	return violations
+}
+
+// coerceHeaderValue turns the raw header string into the type the schema expects,
+// leaving it as a string when it cannot be converted so the type mismatch is reported.
+func coerceHeaderValue(value string, schemaType string) any {
+	switch schemaType {
+	case "integer", "number":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	}
+	return value
+}
+
+func validateContent(oas oas_struct.OAS, content map[string]oas_struct.Content, resp request_sender.Response) []schema_validator.Violation {
+	hasBody := strings.TrimSpace(resp.Body) != ""
+
+	if len(content) == 0 {
+		if hasBody {
+			return []schema_validator.Violation{{Path: "body", Msg: fmt.Sprintf("response has a body but no content is declared for status %d", resp.StatusCode)}}
+		}
+		return nil
+	}
+
+	if !hasBody {
+		return nil
+	}
+
+	contentType := resp.Headers.Get("Content-Type")
+	if contentType == "" {
+		return []schema_validator.Violation{{Path: "header Content-Type", Msg: fmt.Sprintf("missing, expected one of %s", declaredMediaTypes(content))}}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return []schema_validator.Violation{{Path: "header Content-Type", Msg: fmt.Sprintf("invalid media type %q", contentType)}}
+	}
+
+	declaredContent, ok := findDeclaredContent(content, mediaType)
+	if !ok {
+		return []schema_validator.Violation{{Path: "header Content-Type", Msg: fmt.Sprintf("%s is not declared, expected one of %s", mediaType, declaredMediaTypes(content))}}
+	}
+
+	if !isJSONMediaType(mediaType) {
+		return nil
+	}
+
+	var body any
+	if err := json.Unmarshal([]byte(resp.Body), &body); err != nil {
+		return []schema_validator.Violation{{Path: "body", Msg: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+
+	return schema_validator.Validate(declaredContent.Schema, body, oas, "body")
+}
+
+func findDeclaredContent(content map[string]oas_struct.Content, mediaType string) (oas_struct.Content, bool) {
+	if declared, ok := content[mediaType]; ok {
+		return declared, true
+	}
+
+	mainType := strings.SplitN(mediaType, "/", 2)[0]
+	if declared, ok := content[mainType+"/*"]; ok {
+		return declared, true
+	}
+
+	declared, ok := content["*/*"]
+	return declared, ok
+}
+
+func declaredMediaTypes(content map[string]oas_struct.Content) string {
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	return strings.Join(mediaTypes, ", ")
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
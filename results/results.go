@@ -0,0 +1,221 @@
+package results
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexplayer15/parmesan/request_sender"
+)
+
+// Result is the outcome of sending a single request.
+type Result struct {
+	Request    request_sender.Request
+	Response   request_sender.Response
+	Operation  string
+	Err        error
+	Violations []string
+	Failures   []string
+
+	//Assertions counts the response hook assertions checked, of which
+	//FailedAssertions failed. Captured names the variables set from the response.
+	Assertions       int
+	FailedAssertions int
+	Captured         []string
+}
+
+func (r Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Name identifies the result in summaries and reports, preferring the operation from the OAS.
+func (r Result) Name() string {
+	if r.Operation != "" {
+		return r.Operation
+	}
+	if r.Request.Name != "" {
+		return r.Request.Name
+	}
+	return fmt.Sprintf("%s %s", r.Request.Method, r.Request.Url)
+}
+
+const (
+	CriterionNonSuccess = "non-2xx"
+	CriterionSchema     = "schema"
+	CriterionTransport  = "transport"
+	CriterionLatency    = "latency"
+)
+
+// Criteria decides which results count as failures for the run. MaxLatency is
+// only set when latency is one of the criteria.
+type Criteria struct {
+	NonSuccess      bool
+	SchemaViolation bool
+	TransportError  bool
+	MaxLatency      time.Duration
+}
+
+// ParseCriteria reads the fail-on criteria. The latency criterion and a
+// maxLatency limit need each other, so one without the other is an error
+// rather than being silently ignored.
+func ParseCriteria(failOn []string, maxLatency time.Duration) (Criteria, error) {
+	var criteria Criteria
+	failOnLatency := false
+
+	for _, criterion := range failOn {
+		switch strings.ToLower(strings.TrimSpace(criterion)) {
+		case CriterionNonSuccess:
+			criteria.NonSuccess = true
+		case CriterionSchema:
+			criteria.SchemaViolation = true
+		case CriterionTransport:
+			criteria.TransportError = true
+		case CriterionLatency:
+			if maxLatency <= 0 {
+				return Criteria{}, fmt.Errorf("fail-on latency requires --max-latency to be set")
+			}
+			failOnLatency = true
+		case "":
+			continue
+		default:
+			return Criteria{}, fmt.Errorf("unknown fail-on criterion %q, use %s, %s, %s or %s", criterion, CriterionNonSuccess, CriterionSchema, CriterionTransport, CriterionLatency)
+		}
+	}
+
+	if maxLatency < 0 {
+		return Criteria{}, fmt.Errorf("max-latency must not be negative, got %s", maxLatency)
+	}
+	if maxLatency > 0 && !failOnLatency {
+		return Criteria{}, fmt.Errorf("max-latency only fails requests when fail-on includes latency")
+	}
+	if failOnLatency {
+		criteria.MaxLatency = maxLatency
+	}
+
+	return criteria, nil
+}
+
+// Evaluate returns every reason the result fails the criteria, or nil if it passes.
+func (c Criteria) Evaluate(r Result) []string {
+	var failures []string
+
+	if r.Err != nil {
+		if c.TransportError {
+			failures = append(failures, fmt.Sprintf("transport error: %v", r.Err))
+		}
+		return failures
+	}
+
+	if c.NonSuccess && (r.Response.StatusCode < 200 || r.Response.StatusCode > 299) {
+		failures = append(failures, fmt.Sprintf("status %d is not 2xx", r.Response.StatusCode))
+	}
+	if c.SchemaViolation && len(r.Violations) > 0 {
+		failures = append(failures, fmt.Sprintf("%d schema violation(s)", len(r.Violations)))
+	}
+	if c.MaxLatency > 0 && r.Response.Timing.Total > c.MaxLatency {
+		failures = append(failures, fmt.Sprintf("took %s, over the %s limit", r.Response.Timing.Total.Round(time.Millisecond), c.MaxLatency))
+	}
+
+	return failures
+}
+
+type Summary struct {
+	Sent             int
+	Succeeded        int
+	Failed           int
+	Skipped          int
+	StatusClasses    map[string]int
+	Assertions       int
+	FailedAssertions int
+	Captured         []string
+	failures         []failedResult
+	notSent          []string
+}
+
+type failedResult struct {
+	name     string
+	failures []string
+}
+
+func NewSummary() *Summary {
+	return &Summary{StatusClasses: map[string]int{}}
+}
+
+// Add counts a result. Only the names and reasons of failed results are kept,
+// so a summary stays small however many requests are sent.
+func (s *Summary) Add(r Result) {
+	s.Sent++
+	if r.Passed() {
+		s.Succeeded++
+	} else {
+		s.Failed++
+		s.failures = append(s.failures, failedResult{name: r.Name(), failures: r.Failures})
+	}
+	s.StatusClasses[statusClass(r)]++
+	s.Assertions += r.Assertions
+	s.FailedAssertions += r.FailedAssertions
+	s.Captured = append(s.Captured, r.Captured...)
+}
+
+// AddNotSent records a request that was never sent because the run was interrupted.
+func (s *Summary) AddNotSent(name string) {
+	s.notSent = append(s.notSent, name)
+}
+
+func (s *Summary) NotSent() int {
+	return len(s.notSent)
+}
+
+func Summarise(results []Result, skipped int) *Summary {
+	summary := NewSummary()
+	summary.Skipped = skipped
+
+	for _, r := range results {
+		summary.Add(r)
+	}
+
+	return summary
+}
+
+func statusClass(r Result) string {
+	if r.Err != nil {
+		return "transport error"
+	}
+	return fmt.Sprintf("%dxx", r.Response.StatusCode/100)
+}
+
+func (s *Summary) Print(w io.Writer) {
+	for _, failed := range s.failures {
+		fmt.Fprintf(w, "FAIL %s\n", failed.name)
+		for _, failure := range failed.failures {
+			fmt.Fprintf(w, "  - %s\n", failure)
+		}
+	}
+
+	if len(s.notSent) > 0 {
+		fmt.Fprintf(w, "Interrupted before sending %d request(s):\n", len(s.notSent))
+		for _, name := range s.notSent {
+			fmt.Fprintf(w, "  - %s\n", name)
+		}
+	}
+
+	fmt.Fprintf(w, "Sent: %d, Succeeded: %d, Failed: %d, Skipped: %d\n", s.Sent, s.Succeeded, s.Failed, s.Skipped)
+	if s.Assertions > 0 {
+		fmt.Fprintf(w, "Assertions: %d, Passed: %d, Failed: %d\n", s.Assertions, s.Assertions-s.FailedAssertions, s.FailedAssertions)
+	}
+	if len(s.Captured) > 0 {
+		fmt.Fprintf(w, "Captured: %s\n", strings.Join(s.Captured, ", "))
+	}
+
+	classes := make([]string, 0, len(s.StatusClasses))
+	for class := range s.StatusClasses {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	for _, class := range classes {
+		fmt.Fprintf(w, "  %s: %d\n", class, s.StatusClasses[class])
+	}
+}
@@ -0,0 +1,133 @@
+package results
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const RedactedValue = "[REDACTED]"
+
+var DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+type SavedResponse struct {
+	Method        string              `json:"method"`
+	Url           string              `json:"url"`
+	Status        int                 `json:"status"`
+	Response      any                 `json:"response"`
+	Operation     string              `json:"operation,omitempty"`
+	Violations    []string            `json:"violations,omitempty"`
+	Failures      []string            `json:"failures,omitempty"`
+	Error         string              `json:"error,omitempty"`
+	Request       SavedRequest        `json:"request"`
+	Headers       map[string][]string `json:"headers,omitempty"`
+	Protocol      string              `json:"protocol,omitempty"`
+	ContentLength int64               `json:"contentLength"`
+	Timing        SavedTiming         `json:"timing"`
+}
+
+type SavedRequest struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    any               `json:"body,omitempty"`
+}
+
+// SavedTiming is the request timing in milliseconds.
+type SavedTiming struct {
+	DNS     float64 `json:"dnsMs"`
+	Connect float64 `json:"connectMs"`
+	TLS     float64 `json:"tlsMs"`
+	TTFB    float64 `json:"ttfbMs"`
+	Total   float64 `json:"totalMs"`
+}
+
+func NewSavedResponse(r Result) SavedResponse {
+	var sendErr string
+	if r.Err != nil {
+		sendErr = r.Err.Error()
+	}
+
+	return SavedResponse{
+		Method:     r.Request.Method,
+		Url:        r.Request.Url,
+		Status:     r.Response.StatusCode,
+		Response:   parseBody(r, r.Response.Body),
+		Operation:  r.Operation,
+		Violations: r.Violations,
+		Failures:   r.Failures,
+		Error:      sendErr,
+		Request: SavedRequest{
+			Headers: r.Request.Headers,
+			Body:    parseBody(r, r.Request.Body),
+		},
+		Headers:       r.Response.Headers,
+		Protocol:      r.Response.Proto,
+		ContentLength: r.Response.ContentLength,
+		Timing: SavedTiming{
+			DNS:     milliseconds(r.Response.Timing.DNS),
+			Connect: milliseconds(r.Response.Timing.Connect),
+			TLS:     milliseconds(r.Response.Timing.TLS),
+			TTFB:    milliseconds(r.Response.Timing.TTFB),
+			Total:   milliseconds(r.Response.Timing.Total),
+		},
+	}
+}
+
+func parseBody(r Result, body string) any {
+	if strings.TrimSpace(body) == "" {
+		return nil
+	}
+
+	var parsedBody any
+	if err := json.Unmarshal([]byte(body), &parsedBody); err != nil {
+		log.Printf("Failed to parse JSON body for %s %s: %v. Saving as string.", r.Request.Method, r.Request.Url, err)
+		return body
+	}
+	return parsedBody
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// Redacted returns a copy of the result with the values of the named request and
+// response headers replaced, so secrets are not written to output files.
+func (r Result) Redacted(headerNames []string) Result {
+	if len(headerNames) == 0 {
+		return r
+	}
+
+	if r.Request.Headers != nil {
+		requestHeaders := make(map[string]string, len(r.Request.Headers))
+		for key, value := range r.Request.Headers {
+			if shouldRedact(key, headerNames) {
+				value = RedactedValue
+			}
+			requestHeaders[key] = value
+		}
+		r.Request.Headers = requestHeaders
+	}
+
+	if r.Response.Headers != nil {
+		responseHeaders := make(http.Header, len(r.Response.Headers))
+		for key, values := range r.Response.Headers {
+			if shouldRedact(key, headerNames) {
+				values = []string{RedactedValue}
+			}
+			responseHeaders[key] = values
+		}
+		r.Response.Headers = responseHeaders
+	}
+
+	return r
+}
+
+func shouldRedact(header string, headerNames []string) bool {
+	for _, name := range headerNames {
+		if strings.EqualFold(strings.TrimSpace(name), header) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package runtime_expression
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/alexplayer15/parmesan/jsonpointer"
+	"github.com/alexplayer15/parmesan/request_sender"
+)
+
+// Runtime expressions are how an OAS link refers to values in a request or
+// response:
+//
+//	$url  $method  $statusCode
+//	$request.path.id  $request.query.page  $request.header.X-Tenant
+//	$request.body#/user/id  $response.header.Location  $response.body#/id
+//
+// They can also be embedded in a string in braces, e.g. /users/{$response.body#/id}.
+
+// Exchange is the request and response an expression is evaluated against.
+// PathParams holds the values the request filled into its path template.
+type Exchange struct {
+	Request    request_sender.Request
+	Response   request_sender.Response
+	PathParams map[string]string
+}
+
+type Expression struct {
+	raw     string
+	source  string
+	part    string
+	name    string
+	pointer string
+}
+
+var embeddedPattern = regexp.MustCompile(`\{(\$[^{}]+)\}`)
+
+// IsExpression reports whether value is a bare runtime expression.
+func IsExpression(value string) bool {
+	for _, prefix := range []string{"$url", "$method", "$statusCode", "$request.", "$response."} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTemplate reports whether value embeds runtime expressions in braces.
+func IsTemplate(value string) bool {
+	return embeddedPattern.MatchString(value)
+}
+
+func Parse(expr string) (Expression, error) {
+	switch expr {
+	case "$url", "$method", "$statusCode":
+		return Expression{raw: expr, source: strings.TrimPrefix(expr, "$")}, nil
+	}
+
+	source, rest, ok := strings.Cut(strings.TrimPrefix(expr, "$"), ".")
+	if !ok || (source != "request" && source != "response") {
+		return Expression{}, fmt.Errorf("invalid runtime expression %q", expr)
+	}
+
+	e := Expression{raw: expr, source: source}
+
+	if rest == "body" || strings.HasPrefix(rest, "body#") {
+		e.part = "body"
+		e.pointer = strings.TrimPrefix(strings.TrimPrefix(rest, "body"), "#")
+		if _, err := jsonpointer.Parse(e.pointer); err != nil {
+			return Expression{}, fmt.Errorf("invalid runtime expression %q: %w", expr, err)
+		}
+		return e, nil
+	}
+
+	part, name, ok := strings.Cut(rest, ".")
+	if !ok || name == "" {
+		return Expression{}, fmt.Errorf("invalid runtime expression %q: expected header, query, path or body", expr)
+	}
+
+	switch {
+	case part == "header":
+	case source == "request" && (part == "query" || part == "path"):
+	default:
+		return Expression{}, fmt.Errorf("invalid runtime expression %q: %s has no %s", expr, source, part)
+	}
+
+	e.part, e.name = part, name
+	return e, nil
+}
+
+func (e Expression) String() string {
+	return e.raw
+}
+
+func (e Expression) Evaluate(exchange Exchange) (any, error) {
+	switch e.source {
+	case "url":
+		return exchange.Request.Url, nil
+	case "method":
+		return exchange.Request.Method, nil
+	case "statusCode":
+		return exchange.Response.StatusCode, nil
+	}
+
+	switch {
+	case e.source == "response" && e.part == "header":
+		values := exchange.Response.Headers.Values(e.name)
+		if len(values) == 0 {
+			return nil, fmt.Errorf("%s: response has no %s header", e.raw, e.name)
+		}
+		return values[0], nil
+	case e.source == "response":
+		return evaluateBody(e, exchange.Response.Body)
+	case e.part == "header":
+		for name, value := range exchange.Request.Headers {
+			if strings.EqualFold(name, e.name) {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("%s: request has no %s header", e.raw, e.name)
+	case e.part == "path":
+		value, ok := exchange.PathParams[e.name]
+		if !ok {
+			return nil, fmt.Errorf("%s: request has no %s path parameter", e.raw, e.name)
+		}
+		return value, nil
+	case e.part == "query":
+		return evaluateQuery(e, exchange.Request.Url)
+	}
+
+	return evaluateBody(e, exchange.Request.Body)
+}
+
+func evaluateBody(e Expression, body string) (any, error) {
+	var decoded any
+	if err := json.Unmarshal([]byte(body), &decoded); err != nil {
+		if e.pointer == "" {
+			return body, nil
+		}
+		return nil, fmt.Errorf("%s: %s body is not JSON", e.raw, e.source)
+	}
+
+	value, err := jsonpointer.Get(decoded, e.pointer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.raw, err)
+	}
+	return value, nil
+}
+
+func evaluateQuery(e Expression, rawURL string) (any, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.raw, err)
+	}
+
+	values := parsedURL.Query()[e.name]
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%s: request has no %s query parameter", e.raw, e.name)
+	}
+	return values[0], nil
+}
+
+// EvaluateTemplate replaces every {$expression} embedded in template with its
+// value.
+func EvaluateTemplate(template string, exchange Exchange) (string, error) {
+	var evalErr error
+
+	result := embeddedPattern.ReplaceAllStringFunc(template, func(embedded string) string {
+		expr, err := Parse(embedded[1 : len(embedded)-1])
+		if err == nil {
+			var value any
+			if value, err = expr.Evaluate(exchange); err == nil {
+				return Stringify(value)
+			}
+		}
+		if evalErr == nil {
+			evalErr = err
+		}
+		return embedded
+	})
+
+	return result, evalErr
+}
+
+// Stringify formats a value for use in a URL, header or template. Strings are
+// used as they are, everything else as JSON.
+func Stringify(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,370 @@
+package schema_validator
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+)
+
+type Violation struct {
+	Path string
+	Msg  string
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Msg
+	}
+	return fmt.Sprintf("%s: %s", v.Path, v.Msg)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Validate checks a decoded JSON value against a schema and returns every violation found.
+// path is the location of value, used as the prefix of each reported violation.
+func Validate(schema oas_struct.Schema, value any, oas oas_struct.OAS, path string) []Violation {
+	schema, err := oas.ResolveSchema(schema)
+	if err != nil {
+		return []Violation{{Path: path, Msg: err.Error()}}
+	}
+
+	var violations []Violation
+
+	for _, sub := range schema.AllOf {
+		violations = append(violations, Validate(sub, value, oas, path)...)
+	}
+
+	if len(schema.OneOf) > 0 {
+		matches := countMatchingSchemas(schema.OneOf, value, oas, path)
+		if matches != 1 {
+			violations = append(violations, Violation{Path: path, Msg: fmt.Sprintf("expected value to match exactly one oneOf schema, matched %d", matches)})
+		}
+	}
+
+	if len(schema.AnyOf) > 0 {
+		if countMatchingSchemas(schema.AnyOf, value, oas, path) == 0 {
+			violations = append(violations, Violation{Path: path, Msg: "expected value to match at least one anyOf schema"})
+		}
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return violations
+		}
+		return append(violations, Violation{Path: path, Msg: fmt.Sprintf("expected %s, got null", schema.Type)})
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		violations = append(violations, Violation{Path: path, Msg: fmt.Sprintf("value %v is not one of the allowed values %v", value, schema.Enum)})
+	}
+
+	if typeViolation := checkType(schema.Type, value); typeViolation != "" {
+		return append(violations, Violation{Path: path, Msg: typeViolation})
+	}
+
+	if formatViolation := checkFormat(schema.Format, value); formatViolation != "" {
+		violations = append(violations, Violation{Path: path, Msg: formatViolation})
+	}
+
+	for _, boundViolation := range checkBounds(schema, value) {
+		violations = append(violations, Violation{Path: path, Msg: boundViolation})
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		violations = append(violations, validateObject(schema, typed, oas, path)...)
+	case []any:
+		if schema.Items != nil {
+			for i, item := range typed {
+				violations = append(violations, Validate(*schema.Items, item, oas, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+
+	return violations
+}
+
+func validateObject(schema oas_struct.Schema, object map[string]any, oas oas_struct.OAS, path string) []Violation {
+	var violations []Violation
+
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			violations = append(violations, Violation{Path: joinPath(path, name), Msg: "required property is missing"})
+		}
+	}
+
+	//sort keys so violations are reported in a stable order
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		propertyPath := joinPath(path, key)
+		if prop, ok := schema.Properties[key]; ok {
+			violations = append(violations, Validate(prop.AsSchema(), object[key], oas, propertyPath)...)
+			continue
+		}
+
+		if schema.AdditionalProperties == nil {
+			continue
+		}
+		if !schema.AdditionalProperties.Allowed {
+			violations = append(violations, Violation{Path: propertyPath, Msg: "property is not defined in the schema and additionalProperties is false"})
+			continue
+		}
+		if schema.AdditionalProperties.Schema != nil {
+			violations = append(violations, Validate(*schema.AdditionalProperties.Schema, object[key], oas, propertyPath)...)
+		}
+	}
+
+	return violations
+}
+
+func countMatchingSchemas(schemas []oas_struct.Schema, value any, oas oas_struct.OAS, path string) int {
+	matches := 0
+	for _, sub := range schemas {
+		if len(Validate(sub, value, oas, path)) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+func checkType(schemaType string, value any) string {
+	switch schemaType {
+	case "":
+		return ""
+	case "string":
+		if _, ok := value.(string); ok {
+			return ""
+		}
+	case "number":
+		if _, ok := toFloat(value); ok {
+			return ""
+		}
+	case "integer":
+		if number, ok := toFloat(value); ok && number == math.Trunc(number) {
+			return ""
+		}
+	case "boolean":
+		if _, ok := value.(bool); ok {
+			return ""
+		}
+	case "array":
+		if _, ok := value.([]any); ok {
+			return ""
+		}
+	case "object":
+		if _, ok := value.(map[string]any); ok {
+			return ""
+		}
+	default:
+		return ""
+	}
+
+	return fmt.Sprintf("expected %s, got %s", schemaType, jsonTypeName(value))
+}
+
+func checkFormat(format string, value any) string {
+	if str, ok := value.(string); ok {
+		return checkStringFormat(format, str)
+	}
+	if number, ok := toFloat(value); ok {
+		return checkNumberFormat(format, number)
+	}
+	return ""
+}
+
+func checkStringFormat(format string, value string) string {
+	valid := true
+
+	switch format {
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		valid = err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		valid = err == nil
+	case "email":
+		_, err := mail.ParseAddress(value)
+		valid = err == nil
+	case "uuid":
+		valid = uuidPattern.MatchString(value)
+	case "uri":
+		parsedURL, err := url.Parse(value)
+		valid = err == nil && parsedURL.Scheme != ""
+	case "ipv4":
+		ip := net.ParseIP(value)
+		valid = ip != nil && ip.To4() != nil
+	case "ipv6":
+		ip := net.ParseIP(value)
+		valid = ip != nil && ip.To4() == nil
+	}
+
+	if !valid {
+		return fmt.Sprintf("%q is not a valid %s", value, format)
+	}
+	return ""
+}
+
+func checkNumberFormat(format string, value float64) string {
+	switch format {
+	case "int32":
+		if value < math.MinInt32 || value > math.MaxInt32 {
+			return fmt.Sprintf("%v is out of range for int32", value)
+		}
+	case "int64":
+		if value < math.MinInt64 || value > math.MaxInt64 {
+			return fmt.Sprintf("%v is out of range for int64", value)
+		}
+	}
+	return ""
+}
+
+// checkBounds checks minimum and maximum for numbers, minLength, maxLength and
+// pattern for strings, and minItems and maxItems for arrays. exclusiveMinimum
+// and exclusiveMaximum are flags in OAS 3.0 and bounds of their own in 3.1.
+func checkBounds(schema oas_struct.Schema, value any) []string {
+	var violations []string
+
+	if number, ok := toFloat(value); ok {
+		if limit, exclusive, ok := lowerBound(schema); ok && (number < limit || exclusive && number == limit) {
+			violations = append(violations, fmt.Sprintf("%v is less than the minimum of %v%s", number, limit, exclusiveSuffix(exclusive)))
+		}
+		if limit, exclusive, ok := upperBound(schema); ok && (number > limit || exclusive && number == limit) {
+			violations = append(violations, fmt.Sprintf("%v is greater than the maximum of %v%s", number, limit, exclusiveSuffix(exclusive)))
+		}
+	}
+
+	if text, ok := value.(string); ok {
+		length := utf8.RuneCountInString(text)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			violations = append(violations, fmt.Sprintf("%q is shorter than the minimum length of %d", text, *schema.MinLength))
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			violations = append(violations, fmt.Sprintf("%q is longer than the maximum length of %d", text, *schema.MaxLength))
+		}
+		if schema.Pattern != "" {
+			pattern, err := regexp.Compile(schema.Pattern)
+			if err != nil {
+				violations = append(violations, fmt.Sprintf("the schema pattern %q is not a valid regular expression", schema.Pattern))
+			} else if !pattern.MatchString(text) {
+				violations = append(violations, fmt.Sprintf("%q does not match the pattern %s", text, schema.Pattern))
+			}
+		}
+	}
+
+	if array, ok := value.([]any); ok {
+		if schema.MinItems != nil && len(array) < *schema.MinItems {
+			violations = append(violations, fmt.Sprintf("has %d item(s), fewer than the minimum of %d", len(array), *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(array) > *schema.MaxItems {
+			violations = append(violations, fmt.Sprintf("has %d item(s), more than the maximum of %d", len(array), *schema.MaxItems))
+		}
+	}
+
+	return violations
+}
+
+func lowerBound(schema oas_struct.Schema) (float64, bool, bool) {
+	if limit, ok := toFloat(schema.ExclusiveMinimum); ok {
+		return limit, true, true
+	}
+	if schema.Minimum == nil {
+		return 0, false, false
+	}
+	exclusive, _ := schema.ExclusiveMinimum.(bool)
+	return *schema.Minimum, exclusive, true
+}
+
+func upperBound(schema oas_struct.Schema) (float64, bool, bool) {
+	if limit, ok := toFloat(schema.ExclusiveMaximum); ok {
+		return limit, true, true
+	}
+	if schema.Maximum == nil {
+		return 0, false, false
+	}
+	exclusive, _ := schema.ExclusiveMaximum.(bool)
+	return *schema.Maximum, exclusive, true
+}
+
+func exclusiveSuffix(exclusive bool) string {
+	if exclusive {
+		return " (exclusive)"
+	}
+	return ""
+}
+
+func enumContains(enum []any, value any) bool {
+	for _, allowed := range enum {
+		if valuesEqual(allowed, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesEqual compares values decoded from YAML and JSON, which disagree on number types.
+func valuesEqual(a any, b any) bool {
+	aNumber, aIsNumber := toFloat(a)
+	bNumber, bIsNumber := toFloat(b)
+	if aIsNumber && bIsNumber {
+		return aNumber == bNumber
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64, int, int64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	if strings.ContainsAny(key, ".[]") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	return path + "." + key
+}
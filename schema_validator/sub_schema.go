@@ -0,0 +1,84 @@
+package schema_validator
+
+import (
+	"fmt"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+)
+
+// PropertySchema returns the schema of the field name of an object schema,
+// looking through allOf, oneOf and anyOf. Fields the schema does not declare
+// take the additionalProperties schema. It errors when the schema cannot have
+// the field, because it is not an object or additionalProperties is false.
+func PropertySchema(oas oas_struct.OAS, schema oas_struct.Schema, name string) (oas_struct.Schema, error) {
+	schema, err := oas.ResolveSchema(schema)
+	if err != nil {
+		return oas_struct.Schema{}, err
+	}
+
+	if property, ok := findProperty(oas, schema, name); ok {
+		return property.AsSchema(), nil
+	}
+
+	if schema.Type != "" && schema.Type != "object" {
+		return oas_struct.Schema{}, fmt.Errorf("the schema is a %s, which has no field %s", schema.Type, name)
+	}
+
+	if schema.AdditionalProperties != nil {
+		if !schema.AdditionalProperties.Allowed {
+			return oas_struct.Schema{}, fmt.Errorf("field %s is not defined in the schema and additionalProperties is false", name)
+		}
+		if schema.AdditionalProperties.Schema != nil {
+			return *schema.AdditionalProperties.Schema, nil
+		}
+	}
+
+	//undeclared fields are allowed by default and can hold anything
+	return oas_struct.Schema{}, nil
+}
+
+// HasProperty reports whether an object schema declares the field name.
+func HasProperty(oas oas_struct.OAS, schema oas_struct.Schema, name string) bool {
+	schema, err := oas.ResolveSchema(schema)
+	if err != nil {
+		return false
+	}
+	_, ok := findProperty(oas, schema, name)
+	return ok
+}
+
+// ItemSchema returns the schema of the elements of an array schema.
+func ItemSchema(oas oas_struct.OAS, schema oas_struct.Schema) (oas_struct.Schema, error) {
+	schema, err := oas.ResolveSchema(schema)
+	if err != nil {
+		return oas_struct.Schema{}, err
+	}
+
+	if schema.Items != nil {
+		return *schema.Items, nil
+	}
+	if schema.Type != "" && schema.Type != "array" {
+		return oas_struct.Schema{}, fmt.Errorf("the schema is a %s, not an array", schema.Type)
+	}
+	return oas_struct.Schema{}, nil
+}
+
+func findProperty(oas oas_struct.OAS, schema oas_struct.Schema, name string) (oas_struct.Property, bool) {
+	if property, ok := schema.Properties[name]; ok {
+		return property, true
+	}
+
+	for _, subs := range [][]oas_struct.Schema{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		for _, sub := range subs {
+			resolved, err := oas.ResolveSchema(sub)
+			if err != nil {
+				continue
+			}
+			if property, ok := findProperty(oas, resolved, name); ok {
+				return property, true
+			}
+		}
+	}
+
+	return oas_struct.Property{}, false
+}
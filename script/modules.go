@@ -0,0 +1,157 @@
+package script
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// hashModule computes checksums and signatures, returned as lowercase hex:
+//
+//	hash.md5(s)  hash.sha1(s)  hash.sha256(s)  hash.sha512(s)
+//	hash.hmac_sha256(key, s)  hash.hmac_sha512(key, s)
+var hashModule = &starlarkstruct.Module{
+	Name: "hash",
+	Members: starlark.StringDict{
+		"md5":         digest("hash.md5", md5.New),
+		"sha1":        digest("hash.sha1", sha1.New),
+		"sha256":      digest("hash.sha256", sha256.New),
+		"sha512":      digest("hash.sha512", sha512.New),
+		"hmac_sha256": hmacDigest("hash.hmac_sha256", sha256.New),
+		"hmac_sha512": hmacDigest("hash.hmac_sha512", sha512.New),
+	},
+}
+
+// base64Module encodes and decodes base64, with url=True for the URL-safe
+// alphabet:
+//
+//	base64.encode(s, url=False)  base64.decode(s, url=False)
+var base64Module = &starlarkstruct.Module{
+	Name: "base64",
+	Members: starlark.StringDict{
+		"encode": starlark.NewBuiltin("base64.encode", base64Encode),
+		"decode": starlark.NewBuiltin("base64.decode", base64Decode),
+	},
+}
+
+func digest(name string, newHash func() hash.Hash) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var data string
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "data", &data); err != nil {
+			return nil, err
+		}
+		h := newHash()
+		h.Write([]byte(data))
+		return starlark.String(hex.EncodeToString(h.Sum(nil))), nil
+	})
+}
+
+func hmacDigest(name string, newHash func() hash.Hash) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var key, data string
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "key", &key, "data", &data); err != nil {
+			return nil, err
+		}
+		mac := hmac.New(newHash, []byte(key))
+		mac.Write([]byte(data))
+		return starlark.String(hex.EncodeToString(mac.Sum(nil))), nil
+	})
+}
+
+func base64Encoding(url bool) *base64.Encoding {
+	if url {
+		return base64.URLEncoding
+	}
+	return base64.StdEncoding
+}
+
+func base64Encode(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var data string
+	var url bool
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "data", &data, "url?", &url); err != nil {
+		return nil, err
+	}
+	return starlark.String(base64Encoding(url).EncodeToString([]byte(data))), nil
+}
+
+func base64Decode(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var data string
+	var url bool
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "data", &data, "url?", &url); err != nil {
+		return nil, err
+	}
+	decoded, err := base64Encoding(url).DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	return starlark.String(decoded), nil
+}
+
+// fromStarlark converts a value a script passes out into the Go types a
+// decoded JSON document uses.
+func fromStarlark(value starlark.Value) (any, error) {
+	switch typed := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(typed), nil
+	case starlark.Int:
+		if n, ok := typed.Int64(); ok {
+			return n, nil
+		}
+		return nil, fmt.Errorf("integer %s is too large", typed)
+	case starlark.Float:
+		return float64(typed), nil
+	case starlark.String:
+		return string(typed), nil
+	case *starlark.List:
+		return fromSequence(typed)
+	case starlark.Tuple:
+		return fromSequence(typed)
+	case *starlark.Dict:
+		converted := make(map[string]any, typed.Len())
+		for _, item := range typed.Items() {
+			key, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, fmt.Errorf("dict keys must be strings, got %s", item[0].Type())
+			}
+			field, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			converted[key] = field
+		}
+		return converted, nil
+	}
+	return nil, fmt.Errorf("cannot use a %s outside of the script", value.Type())
+}
+
+func fromSequence(sequence starlark.Indexable) ([]any, error) {
+	converted := make([]any, sequence.Len())
+	for i := range converted {
+		item, err := fromStarlark(sequence.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = item
+	}
+	return converted, nil
+}
+
+func encodeJSON(value any) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
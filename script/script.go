@@ -0,0 +1,282 @@
+package script
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/variables"
+	"go.starlark.net/lib/json"
+	"go.starlark.net/lib/math"
+	"go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// maxSteps bounds how much work a script may do, so a loop that never ends
+// fails its request instead of hanging the run.
+const maxSteps = 10_000_000
+
+// fileExtension marks a script value as the path of a Starlark file rather
+// than the script itself.
+const fileExtension = ".star"
+
+// fileOptions lets scripts use if, for and while at the top level and assign a
+// global more than once, as a script run once per request naturally does.
+var fileOptions = &syntax.FileOptions{TopLevelControl: true, GlobalReassign: true, While: true, Set: true}
+
+// Script is Starlark code run before a request is sent or after its response
+// arrives. Scripts are sandboxed: they cannot load modules, read files or
+// make network requests, and only see what Env and the request give them.
+type Script struct {
+	name    string
+	program *starlark.Program
+}
+
+// Env is what a script can reach outside of the request and response.
+type Env struct {
+	//Lookup resolves var(name), the same variables {{name}} placeholders use
+	Lookup variables.Lookup
+	//Set stores set_var(name, value) for later requests
+	Set func(name string, value any)
+	//Output receives what the script prints
+	Output io.Writer
+}
+
+// IsFile reports whether a script value is the path of a .star file.
+func IsFile(source string) bool {
+	trimmed := strings.TrimSpace(source)
+	return strings.HasSuffix(trimmed, fileExtension) && !strings.ContainsAny(trimmed, "\n()=")
+}
+
+// Load compiles a script, reading it from a .star file relative to baseDir
+// when source is a path. name identifies an inline script in errors.
+func Load(source string, baseDir string, name string) (*Script, error) {
+	if IsFile(source) {
+		path := strings.TrimSpace(source)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read script %s", path)
+		}
+		source, name = string(content), path
+	}
+
+	_, program, err := starlark.SourceProgramOptions(fileOptions, name, source, predeclared().Has)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+
+	return &Script{name: name, program: program}, nil
+}
+
+// BeforeRequest runs the script with the request as a mutable dict of
+// method, url, headers and body, and returns the request as the script left
+// it. A body set to a dict or list is encoded as JSON.
+func (s *Script) BeforeRequest(req request_sender.Request, env Env) (request_sender.Request, error) {
+	request := requestDict(req)
+	if err := s.run(env, starlark.StringDict{"request": request}); err != nil {
+		return req, err
+	}
+
+	modified, err := requestFromDict(request)
+	if err != nil {
+		return req, fmt.Errorf("script %s: %w", s.name, err)
+	}
+	modified.Name = req.Name
+	return modified, nil
+}
+
+// AfterResponse runs the script with the request and response as read-only
+// dicts. The script fails the request by calling fail().
+func (s *Script) AfterResponse(req request_sender.Request, resp request_sender.Response, env Env) error {
+	request := requestDict(req)
+	request.Freeze()
+	response := responseDict(resp)
+	response.Freeze()
+
+	return s.run(env, starlark.StringDict{"request": request, "response": response})
+}
+
+func (s *Script) run(env Env, values starlark.StringDict) error {
+	thread := &starlark.Thread{
+		Name: s.name,
+		Print: func(_ *starlark.Thread, msg string) {
+			if env.Output != nil {
+				fmt.Fprintf(env.Output, "[%s] %s\n", s.name, msg)
+			}
+		},
+		//scripts cannot load other files
+		Load: func(_ *starlark.Thread, module string) (starlark.StringDict, error) {
+			return nil, fmt.Errorf("cannot load %s, scripts have no access to files", module)
+		},
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+
+	globals := predeclared()
+	for name, value := range values {
+		globals[name] = value
+	}
+	globals["var"] = starlark.NewBuiltin("var", lookupVar(env.Lookup))
+	globals["set_var"] = starlark.NewBuiltin("set_var", setVar(env.Set))
+
+	if _, err := s.program.Init(thread, globals); err != nil {
+		return fmt.Errorf("script %s: %w", s.name, err)
+	}
+	return nil
+}
+
+// predeclared are the names every script can use besides the Starlark
+// built-ins. request, response, var and set_var are bound per run but declared
+// here so the program compiles.
+func predeclared() starlark.StringDict {
+	return starlark.StringDict{
+		"json":     json.Module,
+		"math":     math.Module,
+		"time":     time.Module,
+		"hash":     hashModule,
+		"base64":   base64Module,
+		"uuid":     starlark.NewBuiltin("uuid", uuid),
+		"request":  starlark.None,
+		"response": starlark.None,
+		"var":      starlark.None,
+		"set_var":  starlark.None,
+	}
+}
+
+func lookupVar(lookup variables.Lookup) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var name string
+		var fallback starlark.Value = starlark.None
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name, "default?", &fallback); err != nil {
+			return nil, err
+		}
+		if lookup != nil {
+			if value, ok := lookup(name); ok {
+				return starlark.String(value), nil
+			}
+		}
+		return fallback, nil
+	}
+}
+
+func setVar(set func(string, any)) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var name string
+		var value starlark.Value
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name, "value", &value); err != nil {
+			return nil, err
+		}
+		converted, err := fromStarlark(value)
+		if err != nil {
+			return nil, fmt.Errorf("set_var %s: %w", name, err)
+		}
+		if set != nil {
+			set(name, converted)
+		}
+		return starlark.None, nil
+	}
+}
+
+func uuid(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	return starlark.String(variables.NewUUID()), nil
+}
+
+func requestDict(req request_sender.Request) *starlark.Dict {
+	headers := starlark.NewDict(len(req.Headers))
+	for _, name := range slices.Sorted(maps.Keys(req.Headers)) {
+		_ = headers.SetKey(starlark.String(name), starlark.String(req.Headers[name]))
+	}
+
+	request := starlark.NewDict(4)
+	_ = request.SetKey(starlark.String("method"), starlark.String(req.Method))
+	_ = request.SetKey(starlark.String("url"), starlark.String(req.Url))
+	_ = request.SetKey(starlark.String("headers"), headers)
+	_ = request.SetKey(starlark.String("body"), starlark.String(req.Body))
+	return request
+}
+
+func requestFromDict(request *starlark.Dict) (request_sender.Request, error) {
+	var req request_sender.Request
+	var err error
+
+	if req.Method, err = stringField(request, "method"); err != nil {
+		return req, err
+	}
+	if req.Url, err = stringField(request, "url"); err != nil {
+		return req, err
+	}
+
+	req.Headers = map[string]string{}
+	value, _, _ := request.Get(starlark.String("headers"))
+	headers, ok := value.(*starlark.Dict)
+	if !ok {
+		return req, fmt.Errorf("request[\"headers\"] must be a dict, got %s", typeName(value))
+	}
+	for _, item := range headers.Items() {
+		name, nameOk := starlark.AsString(item[0])
+		header, valueOk := starlark.AsString(item[1])
+		if !nameOk || !valueOk {
+			return req, fmt.Errorf("request[\"headers\"] must map strings to strings, got %s: %s", item[0].Type(), item[1].Type())
+		}
+		req.Headers[name] = header
+	}
+
+	body, _, _ := request.Get(starlark.String("body"))
+	switch typed := body.(type) {
+	case nil, starlark.NoneType:
+		req.Body = ""
+	case starlark.String:
+		req.Body = string(typed)
+	default:
+		converted, err := fromStarlark(typed)
+		if err != nil {
+			return req, fmt.Errorf("request[\"body\"]: %w", err)
+		}
+		if req.Body, err = encodeJSON(converted); err != nil {
+			return req, fmt.Errorf("request[\"body\"]: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+func responseDict(resp request_sender.Response) *starlark.Dict {
+	headers := starlark.NewDict(len(resp.Headers))
+	for _, name := range slices.Sorted(maps.Keys(resp.Headers)) {
+		_ = headers.SetKey(starlark.String(name), starlark.String(strings.Join(resp.Headers.Values(name), ", ")))
+	}
+
+	response := starlark.NewDict(4)
+	_ = response.SetKey(starlark.String("status"), starlark.MakeInt(resp.StatusCode))
+	_ = response.SetKey(starlark.String("headers"), headers)
+	_ = response.SetKey(starlark.String("body"), starlark.String(resp.Body))
+	_ = response.SetKey(starlark.String("latency_ms"), starlark.MakeInt64(resp.Timing.Total.Milliseconds()))
+	return response
+}
+
+func stringField(dict *starlark.Dict, key string) (string, error) {
+	value, _, _ := dict.Get(starlark.String(key))
+	text, ok := starlark.AsString(value)
+	if !ok {
+		return "", fmt.Errorf("request[%q] must be a string, got %s", key, typeName(value))
+	}
+	return text, nil
+}
+
+func typeName(value starlark.Value) string {
+	if value == nil {
+		return "nothing"
+	}
+	return value.Type()
+}
@@ -0,0 +1,64 @@
+package chain_tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/chain"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/runtime_expression"
+	"github.com/alexplayer15/parmesan/variables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenExtractingFromAResponse_ShouldReadStatusHeadersAndBody(t *testing.T) {
+	//Arrange
+	resp := request_sender.Response{
+		StatusCode: 201,
+		Headers:    http.Header{"Location": {"/users/42"}},
+		Body:       `{"id": 42, "roles": ["admin", "user"]}`,
+	}
+
+	cases := map[string]any{
+		"status":          201,
+		"header.Location": "/users/42",
+		"$.id":            float64(42),
+		"/roles/1":        "user",
+		"$.roles[*]":      []any{"admin", "user"},
+	}
+
+	for expr, expected := range cases {
+		//Act
+		value, err := chain.Extract(expr, runtime_expression.Exchange{Response: resp})
+
+		//Assert
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, value, expr)
+	}
+}
+
+func Test_WhenExtractionExpressionIsUnknown_ShouldError(t *testing.T) {
+	//Act
+	_, err := chain.ParseExtraction("id")
+
+	//Assert
+	assert.ErrorContains(t, err, `cannot extract "id"`)
+}
+
+func Test_WhenBodyValueIsASinglePlaceholder_ShouldKeepTheCapturedType(t *testing.T) {
+	//Arrange
+	vars := chain.NewVariables(map[string]any{"total": float64(3), "name": "Theo"})
+	lookup := vars.Lookup()
+
+	//Act
+	resolved := vars.Resolve(map[string]any{
+		"count":    "{{total}}",
+		"spaced":   " {{ total }} ",
+		"greeting": "hello {{name}} x{{total}}",
+	}, lookup)
+
+	//Assert
+	assert.Equal(t, map[string]any{"count": float64(3), "spaced": float64(3), "greeting": "hello Theo x3"}, resolved)
+	assert.Equal(t, "3", variables.Substitute("{{total}}", lookup))
+}
@@ -0,0 +1,150 @@
+package chain_tests
+
+import (
+	"testing"
+
+	"github.com/alexplayer15/parmesan/chain"
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const linkedOAS = `
+openapi: 3.0.0
+info:
+  title: Users API
+paths:
+  /users/{id}:
+    delete:
+      operationId: deleteUser
+      responses:
+        '204':
+          description: Deleted
+    get:
+      operationId: getUser
+      responses:
+        '200':
+          description: OK
+  /users/{id}/orders:
+    get:
+      parameters:
+        - name: X-Owner
+          in: header
+      responses:
+        '200':
+          description: OK
+  /users:
+    post:
+      operationId: createUser
+      responses:
+        '201':
+          description: Created
+          links:
+            GetUser:
+              operationId: getUser
+              parameters:
+                id: $response.body#/id
+            DeleteUser:
+              $ref: '#/components/links/DeleteUser'
+            UserOrders:
+              operationRef: '#/paths/~1users~1{id}~1orders/get'
+              parameters:
+                path.id: $response.body#/id
+                X-Owner: '{$response.body#/name}-owner'
+                limit: 10
+components:
+  links:
+    DeleteUser:
+      operationId: deleteUser
+      parameters:
+        id: $response.body#/id
+`
+
+func parseOAS(t *testing.T, content string) oas_struct.OAS {
+	t.Helper()
+	var oas oas_struct.OAS
+	require.NoError(t, yaml.Unmarshal([]byte(content), &oas))
+	return oas
+}
+
+func Test_WhenOASDeclaresLinks_ShouldOrderProducersFirstAndDeletesLast(t *testing.T) {
+	//Arrange
+	oas := parseOAS(t, linkedOAS)
+
+	//Act
+	workflow, err := chain.WorkflowFromLinks(oas)
+
+	//Assert
+	require.NoError(t, err)
+	var names []string
+	for i, step := range workflow.Steps {
+		names = append(names, step.Label(i))
+	}
+	assert.Equal(t, []string{"createUser", "getUser", "GET /users/{id}/orders", "deleteUser"}, names)
+}
+
+func Test_WhenLinkParametersAreRuntimeExpressions_ShouldExtractThemFromTheProducer(t *testing.T) {
+	//Arrange
+	oas := parseOAS(t, linkedOAS)
+
+	//Act
+	workflow, err := chain.WorkflowFromLinks(oas)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"DeleteUser.id":      "$response.body#/id",
+		"GetUser.id":         "$response.body#/id",
+		"UserOrders.id":      "$response.body#/id",
+		"UserOrders.X-Owner": "{$response.body#/name}-owner",
+	}, workflow.Steps[0].Extract)
+	assert.Equal(t, map[string]string{"id": "{{GetUser.id}}"}, workflow.Steps[1].PathParams)
+
+	orders := workflow.Steps[2]
+	assert.Equal(t, map[string]string{"id": "{{UserOrders.id}}"}, orders.PathParams)
+	assert.Equal(t, map[string]string{"X-Owner": "{{UserOrders.X-Owner}}"}, orders.Headers)
+	assert.Equal(t, map[string]string{"limit": "10"}, orders.Query)
+	assert.NoError(t, workflow.Validate(oas))
+}
+
+func Test_WhenLinksFormACycle_ShouldError(t *testing.T) {
+	//Arrange
+	oas := parseOAS(t, `
+openapi: 3.0.0
+paths:
+  /a:
+    get:
+      operationId: a
+      responses:
+        '200':
+          links:
+            ToB:
+              operationId: b
+  /b:
+    get:
+      operationId: b
+      responses:
+        '200':
+          links:
+            ToA:
+              operationId: a
+`)
+
+	//Act
+	_, err := chain.WorkflowFromLinks(oas)
+
+	//Assert
+	assert.EqualError(t, err, "links form a cycle between a, b")
+}
+
+func Test_WhenOASHasNoLinks_ShouldError(t *testing.T) {
+	//Arrange
+	oas := parseOAS(t, "openapi: 3.0.0\npaths:\n  /a:\n    get:\n      operationId: a\n")
+
+	//Act
+	_, err := chain.WorkflowFromLinks(oas)
+
+	//Assert
+	assert.EqualError(t, err, "the OAS declares no links between operations")
+}
@@ -0,0 +1,291 @@
+package command_tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/results"
+	"github.com/alexplayer15/parmesan/test_helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usersServer is a fake users API that hands out ids from 42 and records every
+// request it receives as "METHOD path".
+type usersServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	received []string
+	bodies   []string
+	users    map[string]map[string]any
+}
+
+func newUsersServer(t *testing.T) *usersServer {
+	s := &usersServer{users: map[string]map[string]any{}}
+	nextId := 42
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		s.received = append(s.received, r.Method+" "+r.URL.RequestURI())
+		s.bodies = append(s.bodies, string(body))
+		w.Header().Set("Content-Type", "application/json")
+
+		id := strings.TrimPrefix(r.URL.Path, "/users/")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/users":
+			user := map[string]any{}
+			json.Unmarshal(body, &user)
+			user["id"] = nextId
+			s.users[fmt.Sprint(nextId)] = user
+			nextId++
+			w.Header().Set("Location", fmt.Sprintf("/users/%d", user["id"]))
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(user)
+		case r.Method == http.MethodGet && r.URL.Path == "/users":
+			json.NewEncoder(w).Encode([]any{})
+		case s.users[id] == nil:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(s.users[id])
+		case r.Method == http.MethodPatch:
+			user := s.users[id]
+			json.Unmarshal(body, &user)
+			json.NewEncoder(w).Encode(s.users[id])
+		case r.Method == http.MethodDelete:
+			delete(s.users, id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func Test_WhenRunningAWorkflow_ShouldFeedExtractedValuesIntoLaterSteps(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	workflow := `
+variables:
+  tenant: acme
+steps:
+  - name: create user
+    operationId: createUser
+    headers:
+      X-Tenant: "{{tenant}}"
+    body:
+      name: Theo
+    extract:
+      userId: $.id
+      location: header.Location
+      createdStatus: status
+  - name: get user
+    path: /users/{id}
+    method: get
+    pathParams:
+      id: "{{userId}}"
+    query:
+      created: "{{createdStatus}}"
+  - operationId: deleteUser
+    pathParams:
+      id: "{{userId}}"
+`
+	cmd, tmpDir := test_helpers.SetupChainRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), workflow)
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"POST /users", "GET /users/42?created=201", "DELETE /users/42"}, server.received)
+	assert.JSONEq(t, `{"name": "Theo"}`, server.bodies[0])
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "workflow.json"))
+	require.NoError(t, err)
+	var saved []results.SavedResponse
+	require.NoError(t, json.Unmarshal(content, &saved))
+	require.Len(t, saved, 3)
+	assert.Equal(t, "acme", saved[0].Request.Headers["X-Tenant"])
+	assert.Equal(t, 204, saved[2].Status)
+}
+
+func Test_WhenAStepFails_ShouldSkipTheRemainingStepsAndExitNonZero(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	workflow := `
+steps:
+  - operationId: getUser
+    pathParams:
+      id: "999"
+  - operationId: listUsers
+`
+	cmd, _ := test_helpers.SetupChainRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), workflow)
+	var out strings.Builder
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrRequestsFailed)
+	assert.Equal(t, []string{"GET /users/999"}, server.received)
+	assert.Contains(t, out.String(), "Sent: 1, Succeeded: 0, Failed: 1, Skipped: 1")
+}
+
+func Test_WhenAStepContinuesOnFailure_ShouldRunTheNextStep(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	workflow := `
+steps:
+  - operationId: getUser
+    continueOnFailure: true
+    pathParams:
+      id: "999"
+  - operationId: listUsers
+`
+	cmd, _ := test_helpers.SetupChainRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), workflow)
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrRequestsFailed)
+	assert.Equal(t, []string{"GET /users/999", "GET /users"}, server.received)
+}
+
+func Test_WhenAnExtractionMatchesNothing_ShouldFailTheStep(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	workflow := `
+steps:
+  - operationId: createUser
+    extract:
+      userId: $.uuid
+  - operationId: getUser
+    pathParams:
+      id: "{{userId}}"
+`
+	cmd, _ := test_helpers.SetupChainRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), workflow)
+	var out strings.Builder
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrRequestsFailed)
+	assert.Equal(t, []string{"POST /users"}, server.received)
+	assert.Contains(t, out.String(), `extract userId: JSONPath "$.uuid" matched nothing in the response body`)
+}
+
+func Test_WhenAStepReferencesAnUnknownOperation_ShouldErrorBeforeSending(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	workflow := `
+steps:
+  - operationId: createUser
+  - operationId: archiveUser
+`
+	cmd, _ := test_helpers.SetupChainRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), workflow)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.EqualError(t, err, `step 2 (archiveUser): no operation with operationId "archiveUser" in the OAS`)
+	assert.Empty(t, server.received)
+}
+
+func Test_WhenChainingByLinks_ShouldCreateThenReadThenDelete(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	oas := strings.Replace(test_helpers.UsersCRUDOAS(server.URL), `        '201':
+          description: Created
+`, `        '201':
+          description: Created
+          links:
+            GetUser:
+              operationId: getUser
+              parameters:
+                id: $response.body#/id
+            DeleteUser:
+              operationId: deleteUser
+              parameters:
+                id: $response.body#/id
+`, 1)
+	cmd, tmpDir := test_helpers.SetupChainRequestTest(t, oas, "", "--links")
+	var out strings.Builder
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"POST /users", "GET /users/42", "DELETE /users/42"}, server.received)
+	assert.Contains(t, out.String(), "Chaining 3 operation(s) from OAS links:\n  1. createUser\n  2. getUser\n  3. deleteUser\n")
+	assert.FileExists(t, filepath.Join(tmpDir, "oas.json"))
+}
+
+func Test_WhenGivingBothAWorkflowAndLinks_ShouldError(t *testing.T) {
+	//Arrange
+	cmd, _ := test_helpers.SetupChainRequestTest(t, test_helpers.UsersCRUDOAS("http://localhost:1"), "steps: []", "--links")
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.EqualError(t, err, "pass either a workflow file or --links to derive the workflow from the OAS links")
+}
+
+func Test_WhenStepsHaveScripts_ShouldRunThemAndFeedTheirVariablesOn(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	workflow := `
+steps:
+  - operationId: createUser
+    body:
+      name: Theo
+    script: |
+      request["url"] += "?checksum=" + hash.sha256(request["body"])[:8]
+    responseScript: |
+      set_var("userId", json.decode(response["body"])["id"])
+  - operationId: getUser
+    pathParams:
+      id: "{{userId}}"
+    responseScript: |
+      if json.decode(response["body"])["name"] != "Bob":
+          fail("fetched", response["body"])
+  - operationId: listUsers
+`
+	cmd, _ := test_helpers.SetupChainRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), workflow)
+	var out strings.Builder
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	require.ErrorIs(t, err, errors.ErrRequestsFailed)
+	require.Len(t, server.received, 2)
+	assert.Regexp(t, `^POST /users\?checksum=[0-9a-f]{8}$`, server.received[0])
+	assert.Equal(t, "GET /users/42", server.received[1])
+	assert.Contains(t, out.String(), "fail: fetched")
+}
@@ -0,0 +1,173 @@
+package command_tests
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/test_helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dryRunHooks = `- path: /users
+  method: POST
+  body:
+    name: Theo
+`
+
+func dryRunOAS(serverURL string) string {
+	return fmt.Sprintf(`openapi: 3.0.0
+info:
+  title: Dry Run Test API
+  version: 1.0.0
+servers:
+  - url: %s
+paths:
+  /users:
+    post:
+      operationId: createUser
+      parameters:
+        - name: X-Tenant
+          in: header
+          example: acme
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                  example: Alex
+      responses:
+        '201':
+          description: Created
+`, serverURL)
+}
+
+func newCountingServer(t *testing.T, count *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*count++
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_WhenDryRunIsSet_ShouldPrintResolvedRequestsWithoutSending(t *testing.T) {
+	//Arrange
+	requestsReceived := 0
+	server := newCountingServer(t, &requestsReceived)
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, dryRunOAS(server.URL), "--dry-run", "--hooks", "hooks.yml")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hooks.yml"), []byte(dryRunHooks), 0644))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, requestsReceived)
+	assert.Contains(t, out.String(), "#### createUser (hook: POST /users)")
+	assert.Contains(t, out.String(), fmt.Sprintf("POST %s/users", server.URL))
+	assert.Contains(t, out.String(), "X-Tenant: acme")
+	assert.Contains(t, out.String(), `"name": "Theo"`)
+	assert.NoFileExists(t, filepath.Join(tmpDir, "oas.json"))
+}
+
+func Test_WhenDryRunIsSetWithOutput_ShouldWriteResolvedRequestsToFile(t *testing.T) {
+	//Arrange
+	requestsReceived := 0
+	server := newCountingServer(t, &requestsReceived)
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, dryRunOAS(server.URL), "--dry-run", "--output", "requests")
+	cmd.SetOut(&bytes.Buffer{})
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, requestsReceived)
+	content, err := os.ReadFile(filepath.Join(tmpDir, "requests", "oas.dry-run.http"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "#### createUser (no hook)")
+}
+
+func Test_WhenHookSetsHeaders_ShouldShowThemInTheDryRun(t *testing.T) {
+	//Arrange
+	requestsReceived := 0
+	server := newCountingServer(t, &requestsReceived)
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, dryRunOAS(server.URL), "--dry-run", "--hooks", "hooks.yml")
+	hooks := `- path: /users
+  method: POST
+  headers:
+    X-Tenant: globex
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hooks.yml"), []byte(hooks), 0644))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "X-Tenant: globex")
+	assert.Contains(t, out.String(), `"name": "Alex"`)
+}
+
+func Test_WhenHookSetsAnUndeclaredQueryParameter_ShouldError(t *testing.T) {
+	//Arrange
+	requestsReceived := 0
+	server := newCountingServer(t, &requestsReceived)
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, dryRunOAS(server.URL), "--dry-run", "--hooks", "hooks.yml")
+	hooks := `- path: /users
+  method: POST
+  query:
+    dryRun: "true"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hooks.yml"), []byte(hooks), 0644))
+	cmd.SetOut(&bytes.Buffer{})
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorContains(t, err, "query.dryRun")
+}
+
+func Test_WhenHookUsesVariables_ShouldResolveThemInTheDryRun(t *testing.T) {
+	//Arrange
+	t.Setenv("PARMESAN_TEST_TENANT", "initech")
+	requestsReceived := 0
+	server := newCountingServer(t, &requestsReceived)
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, dryRunOAS(server.URL), "--dry-run", "--hooks", "hooks.yml")
+	hooks := `- path: /users
+  method: POST
+  headers:
+    X-Tenant: "{{$env.PARMESAN_TEST_TENANT}}"
+  body:
+    name: "{{$env.PARMESAN_TEST_TENANT}}"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hooks.yml"), []byte(hooks), 0644))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "X-Tenant: initech")
+	assert.Contains(t, out.String(), `"name": "initech"`)
+	assert.NotContains(t, out.String(), "{{")
+}
@@ -0,0 +1,78 @@
+package command_tests
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/test_helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenHooksFileMatchesTheSpec_ShouldReportNoProblems(t *testing.T) {
+	//Arrange
+	cmd, _ := test_helpers.SetupHooksLintTest(t, dryRunOAS("http://localhost:8080"), dryRunHooks)
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "hooks.yml: no problems found")
+}
+
+func Test_WhenHooksFileHasProblems_ShouldReportThemWithPositionsAndFail(t *testing.T) {
+	//Arrange
+	hooks := `- path: /usres
+  method: POST
+  body:
+    name: Theo
+- operationId: createUser
+  body:
+    nmae: Theo
+  headers:
+    X-Tenant: globex
+    X-Debug: "true"
+`
+	cmd, _ := test_helpers.SetupHooksLintTest(t, dryRunOAS("http://localhost:8080"), hooks)
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrHooksLintFailed)
+	assert.Contains(t, out.String(), "hooks.yml:1:9: hook 1 (POST /usres): path /usres matches no operation in the OAS")
+	assert.Contains(t, out.String(), "hooks.yml:7:5: hook 2 (* operationId createUser): createUser: body field nmae: nmae is not defined in the request body schema")
+	assert.Contains(t, out.String(), "hooks.yml:10:5: hook 2 (* operationId createUser): createUser does not declare a header parameter X-Debug")
+	assert.NotContains(t, out.String(), "X-Tenant")
+}
+
+func Test_WhenLintingAHooksDirectory_ShouldCheckJSONAndIncludedFiles(t *testing.T) {
+	//Arrange
+	cmd, tmpDir := test_helpers.SetupHooksLintTest(t, dryRunOAS("http://localhost:8080"), dryRunHooks)
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "hooks", "team"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hooks", "main.yml"), []byte("- include: ../hooks.yml\n- include: shared/missing.yml\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hooks", "team", "orders.json"), []byte(`[{"path": "/orders"}]`), 0644))
+	cmd.SetArgs([]string{"hooks", "lint", "oas.yml", "hooks"})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrHooksLintFailed)
+	assert.Contains(t, out.String(), "hooks/main.yml:2:12: hook 2: cannot read included hooks hooks/shared/missing.yml")
+	assert.Contains(t, out.String(), "hooks/team/orders.json:1:11: hook 1 (* /orders): path /orders matches no operation in the OAS")
+	assert.Contains(t, err.Error(), "2 problem(s)")
+}
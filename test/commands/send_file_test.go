@@ -0,0 +1,200 @@
+package command_tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/results"
+	"github.com/alexplayer15/parmesan/test_helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenSendingAHandEditedHttpFile_ShouldSendItsRequestsAndSaveResponses(t *testing.T) {
+	//Arrange
+	var receivedTenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedTenant = r.Header.Get("X-Tenant")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	httpFile := fmt.Sprintf("#### Summary: List users\nGET %s/users\nX-Tenant: edited-by-hand\n\n", server.URL)
+	cmd, tmpDir := test_helpers.SetupSendFileTest(t, "requests.http", httpFile)
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "edited-by-hand", receivedTenant)
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "requests.json"))
+	require.NoError(t, err)
+	var saved []results.SavedResponse
+	require.NoError(t, json.Unmarshal(content, &saved))
+	require.Len(t, saved, 1)
+	assert.Equal(t, 200, saved[0].Status)
+}
+
+func Test_WhenARequestCannotBeSent_ShouldSaveItWithTheError(t *testing.T) {
+	//Arrange
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	httpFile := fmt.Sprintf("GET %s/users\n", server.URL)
+	cmd, tmpDir := test_helpers.SetupSendFileTest(t, "requests.http", httpFile)
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrRequestsFailed)
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "requests.json"))
+	require.NoError(t, err)
+	var saved []results.SavedResponse
+	require.NoError(t, json.Unmarshal(content, &saved))
+	require.Len(t, saved, 1)
+	assert.Equal(t, server.URL+"/users", saved[0].Url)
+	assert.Equal(t, 0, saved[0].Status)
+	assert.Contains(t, saved[0].Error, "failed to send HTTP request")
+}
+
+func Test_WhenSendingAFileWithAnUnsupportedExtension_ShouldError(t *testing.T) {
+	//Arrange
+	cmd, _ := test_helpers.SetupSendFileTest(t, "requests.txt", "GET http://localhost:1/users\n")
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.EqualError(t, err, "unsupported file extension: txt, expected a .http or .rest file")
+}
+
+func Test_WhenValidatingResponsesWithoutAnOAS_ShouldError(t *testing.T) {
+	//Arrange
+	cmd, _ := test_helpers.SetupSendFileTest(t, "requests.rest", "#### List users\nGET http://localhost:1/users\n", "--validate-responses")
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.EqualError(t, err, "validating responses needs the OAS, pass it with --oas")
+}
+
+func Test_WhenSendingWithAnEnvironment_ShouldSubstituteItsVariables(t *testing.T) {
+	//Arrange
+	var receivedPath, receivedToken, receivedBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		receivedToken = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(server.Close)
+
+	httpFile := "### Create user\nPOST {{host}}/tenants/{{tenant}}/users\nAuthorization: Bearer {{token}}\n\n{\"id\": \"{{$uuid}}\"}\n"
+	cmd, tmpDir := test_helpers.SetupSendFileTest(t, "requests.http", httpFile, "--env", "dev")
+	cmd.SetOut(io.Discard)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "http-client.env.json"), []byte(fmt.Sprintf(`{"dev": {"host": %q, "tenant": "acme"}}`, server.URL)), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "http-client.private.env.json"), []byte(`{"dev": {"token": "secret"}}`), 0644))
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "/tenants/acme/users", receivedPath)
+	assert.Equal(t, "Bearer secret", receivedToken)
+	assert.Regexp(t, `^\{"id": "[0-9a-f-]{36}"\}$`, receivedBody)
+}
+
+func Test_WhenAVariableIsNotDefined_ShouldErrorBeforeSending(t *testing.T) {
+	//Arrange
+	requestsReceived := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestsReceived++
+	}))
+	t.Cleanup(server.Close)
+
+	httpFile := fmt.Sprintf("GET %s/users/{{userId}}\n", server.URL)
+	cmd, _ := test_helpers.SetupSendFileTest(t, "requests.http", httpFile)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	var ve *errors.ValidationError
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, errors.ErrCodeUnresolvedVar, ve.Code)
+	assert.Equal(t, "userId", ve.Value)
+	assert.Equal(t, 0, requestsReceived)
+}
+
+func Test_WhenRunIsCancelled_ShouldNotResolveTheVariablesOfRequestsNotSent(t *testing.T) {
+	//Arrange
+	httpFile := "GET http://localhost/users/{{userId}}\n"
+	cmd, _ := test_helpers.SetupSendFileTest(t, "requests.http", httpFile)
+	cmd.SetOut(io.Discard)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	//Act
+	err := cmd.ExecuteContext(ctx)
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrRunInterrupted)
+}
+
+func Test_WhenAFilteredOutRequestHasAnUndefinedVariable_ShouldStillSendTheOthers(t *testing.T) {
+	//Arrange
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Method+" "+r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+
+	httpFile := fmt.Sprintf("GET %s/users/{{userId}}\n\n###\n\nPOST %s/users\n", server.URL, server.URL)
+	cmd, _ := test_helpers.SetupSendFileTest(t, "requests.http", httpFile, "--method", "POST")
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"POST /users"}, received)
+}
+
+func Test_WhenAFilteredOutRequestHasAnUndefinedVariable_ShouldStillDryRunTheOthers(t *testing.T) {
+	//Arrange
+	httpFile := "GET http://localhost/users/{{userId}}\n\n###\n\nPOST http://localhost/users\n"
+	cmd, _ := test_helpers.SetupSendFileTest(t, "requests.http", httpFile, "--dry-run", "--method", "POST")
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "POST http://localhost/users")
+	assert.NotContains(t, out.String(), "{{userId}}")
+}
@@ -0,0 +1,341 @@
+package command_tests
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/test_helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJSONServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_WhenAllRequestsSucceed_ShouldNotError(t *testing.T) {
+	//Arrange
+	server := newJSONServer(t, http.StatusOK, `{"id": 1}`)
+	cmd, _ := test_helpers.SetupSendRequestTest(t, test_helpers.SendRequestOAS(server.URL))
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.NoError(t, err)
+}
+
+func Test_WhenARequestReturnsNon2xx_ShouldFailTheRun(t *testing.T) {
+	//Arrange
+	server := newJSONServer(t, http.StatusInternalServerError, `{}`)
+	cmd, _ := test_helpers.SetupSendRequestTest(t, test_helpers.SendRequestOAS(server.URL))
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrRequestsFailed)
+}
+
+func Test_WhenFailOnIsEmpty_ShouldNotFailTheRunOnNon2xx(t *testing.T) {
+	//Arrange
+	server := newJSONServer(t, http.StatusInternalServerError, `{}`)
+	cmd, _ := test_helpers.SetupSendRequestTest(t, test_helpers.SendRequestOAS(server.URL), "--fail-on", "")
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.NoError(t, err)
+}
+
+func Test_WhenFailOnSchemaAndResponseBreaksSchema_ShouldFailTheRun(t *testing.T) {
+	//Arrange
+	server := newJSONServer(t, http.StatusOK, `{"id": "one"}`)
+	cmd, _ := test_helpers.SetupSendRequestTest(t, test_helpers.SendRequestOAS(server.URL), "--fail-on", "schema")
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrRequestsFailed)
+}
+
+func Test_WhenFailOnHasUnknownCriterion_ShouldError(t *testing.T) {
+	//Arrange
+	cmd, _ := test_helpers.SetupSendRequestTest(t, test_helpers.SendRequestOAS("http://localhost:1"), "--fail-on", "banana")
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.EqualError(t, err, `unknown fail-on criterion "banana", use non-2xx, schema, transport or latency`)
+}
+
+func Test_WhenReportFlagIsUsed_ShouldWriteRequestedReports(t *testing.T) {
+	//Arrange
+	server := newJSONServer(t, http.StatusOK, `{"id": 1}`)
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, test_helpers.SendRequestOAS(server.URL), "--report", "junit,html")
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(tmpDir, "oas.junit.xml"))
+	assert.FileExists(t, filepath.Join(tmpDir, "oas.html"))
+}
+
+func Test_WhenReportTypeIsUnknown_ShouldError(t *testing.T) {
+	//Arrange
+	cmd, _ := test_helpers.SetupSendRequestTest(t, test_helpers.SendRequestOAS("http://localhost:1"), "--report", "pdf")
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.EqualError(t, err, `unknown report type "pdf", use junit or html`)
+}
+
+func Test_WhenRunIsCancelled_ShouldStopSendingAndSaveValidOutput(t *testing.T) {
+	//Arrange
+	requestsReceived := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestsReceived++
+	}))
+	t.Cleanup(server.Close)
+
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, test_helpers.SendRequestOAS(server.URL))
+	cmd.SetOut(io.Discard)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	//Act
+	err := cmd.ExecuteContext(ctx)
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrRunInterrupted)
+	assert.Equal(t, 0, requestsReceived)
+	content, readErr := os.ReadFile(filepath.Join(tmpDir, "oas.json"))
+	assert.NoError(t, readErr)
+	assert.JSONEq(t, `[]`, string(content))
+}
+
+func Test_WhenSendingCRUDOperations_ShouldCreateFirstAndFeedTheIdThrough(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	cmd, _ := test_helpers.SetupSendRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), "--plan")
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"POST /users", "GET /users", "GET /users/42", "PATCH /users/42", "DELETE /users/42"}, server.received)
+}
+
+func Test_WhenPlanIsNotPassed_ShouldNotFillCreatedIds(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	cmd, _ := test_helpers.SetupSendRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), "--fail-on=")
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.NoError(t, err)
+	assert.Len(t, server.received, 5)
+	assert.NotContains(t, server.received, "GET /users/42")
+}
+
+func Test_WhenResponseHooksPassAndCapture_ShouldUseTheCapturedValuesLater(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), "--hooks", "hooks.yml", "--plan")
+	hooks := `- operationId: createUser
+  response:
+    status: [200, 201]
+    headers: [Location]
+    maxLatency: 5s
+    body:
+      - path: $.name
+        equals: Alex
+      - path: $.id
+        matches: '^\d+$'
+    capture:
+      createdId: $.id
+- operationId: updateUser
+  body:
+    name: "user {{createdId}}"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hooks.yml"), []byte(hooks), 0644))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "user 42"}`, server.bodies[3])
+	assert.Contains(t, out.String(), "Assertions: 5, Passed: 5, Failed: 0")
+	assert.Contains(t, out.String(), "Captured: createdId")
+}
+
+func Test_WhenACapturedIdFeedsAnIntegerField_ShouldSendItAsANumber(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	oas := strings.Replace(test_helpers.UsersCRUDOAS(server.URL), "    NewUser:\n      type: object\n      properties:\n",
+		"    NewUser:\n      type: object\n      properties:\n        managerId:\n          type: integer\n", 1)
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, oas, "--hooks", "hooks.yml", "--plan")
+	hooks := `- operationId: createUser
+  response:
+    capture:
+      createdId: $.id
+- operationId: updateUser
+  body:
+    managerId: "{{ createdId }}"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hooks.yml"), []byte(hooks), 0644))
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "PATCH /users/42", server.received[3])
+	assert.JSONEq(t, `{"name": "Alex", "managerId": 42}`, server.bodies[3])
+}
+
+func Test_WhenAResponseHookAssertionFails_ShouldFailTheRequest(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), "--hooks", "hooks.yml")
+	hooks := `- operationId: createUser
+  response:
+    status: [200]
+    body:
+      - path: $.name
+        equals: Bob
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hooks.yml"), []byte(hooks), 0644))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrRequestsFailed)
+	assert.Contains(t, out.String(), "FAIL createUser")
+	assert.Contains(t, out.String(), "assertion failed: status 201 is not one of [200]")
+	assert.Contains(t, out.String(), `assertion failed: $.name: expected "Bob", got "Alex"`)
+	assert.Contains(t, out.String(), "Assertions: 2, Passed: 0, Failed: 2")
+}
+
+func Test_WhenHooksHaveScripts_ShouldRunThemBeforeAndAfterTheRequest(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), "--hooks", "hooks.yml", "--plan")
+	hooks := `- operationId: createUser
+  script: |
+    body = json.decode(request["body"])
+    body["name"] = body["name"].upper()
+    request["body"] = body
+  response:
+    script: |
+      user = json.decode(response["body"])
+      set_var("createdName", user["name"] + " " + str(user["id"]))
+- operationId: updateUser
+  body:
+    name: "{{createdName}}"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hooks.yml"), []byte(hooks), 0644))
+	cmd.SetOut(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "ALEX"}`, server.bodies[0])
+	assert.JSONEq(t, `{"name": "ALEX 42"}`, server.bodies[3])
+}
+
+func Test_WhenAResponseScriptFails_ShouldFailTheRequest(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	cmd, tmpDir := test_helpers.SetupSendRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), "--hooks", "hooks.yml")
+	hooks := `- operationId: createUser
+  response:
+    script: |
+      if response["status"] != 200:
+          fail("expected 200, got", response["status"])
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "hooks.yml"), []byte(hooks), 0644))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrRequestsFailed)
+	assert.Contains(t, out.String(), "FAIL createUser")
+	assert.Contains(t, out.String(), "expected 200, got 201")
+}
+
+func Test_WhenMaxLatencyIsSetWithoutFailOn_ShouldFailSlowResponses(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	cmd, _ := test_helpers.SetupSendRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), "--max-latency", "1ns")
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrRequestsFailed)
+	assert.Contains(t, out.String(), "over the 1ns limit")
+}
+
+func Test_WhenMaxLatencyIsSetWithAFailOnWithoutLatency_ShouldError(t *testing.T) {
+	//Arrange
+	server := newUsersServer(t)
+	cmd, _ := test_helpers.SetupSendRequestTest(t, test_helpers.UsersCRUDOAS(server.URL), "--max-latency", "1s", "--fail-on", "non-2xx")
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	//Act
+	err := cmd.Execute()
+
+	//Assert
+	assert.EqualError(t, err, "max-latency only fails requests when fail-on includes latency")
+	assert.Empty(t, server.received)
+}
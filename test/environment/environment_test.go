@@ -0,0 +1,103 @@
+package environment_tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/environment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func Test_WhenPrivateEnvFileDefinesAVariable_ShouldOverrideThePublicOne(t *testing.T) {
+	//Arrange
+	dir := t.TempDir()
+	writeFile(t, dir, environment.PublicEnvFile, `{"dev": {"host": "http://dev", "token": "public"}}`)
+	writeFile(t, dir, environment.PrivateEnvFile, `{"dev": {"token": "secret"}}`)
+
+	//Act
+	vars, err := environment.Load(dir, "dev")
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "http://dev", "token": "secret"}, vars)
+}
+
+func Test_WhenSharedEnvironmentIsDefined_ShouldBeInheritedAndOverridable(t *testing.T) {
+	//Arrange
+	dir := t.TempDir()
+	writeFile(t, dir, environment.PublicEnvFile, `{
+		"$shared": {"version": "v1", "host": "http://shared"},
+		"dev": {"host": "http://dev", "userId": 12345678901, "debug": true}
+	}`)
+
+	//Act
+	vars, err := environment.Load(dir, "dev")
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "v1", vars["version"])
+	assert.Equal(t, "http://dev", vars["host"])
+	assert.Equal(t, "12345678901", vars["userId"])
+	assert.Equal(t, "true", vars["debug"])
+}
+
+func Test_WhenSharedEnvironmentIsInALaterFile_ShouldNotOverrideTheNamedOne(t *testing.T) {
+	//Arrange
+	dir := t.TempDir()
+	writeFile(t, dir, environment.PublicEnvFile, `{"dev": {"host": "http://dev"}}`)
+	writeFile(t, dir, environment.PrivateEnvFile, `{"$shared": {"host": "http://shared", "token": "secret"}}`)
+
+	//Act
+	vars, err := environment.Load(dir, "dev")
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "http://dev", "token": "secret"}, vars)
+}
+
+func Test_WhenYAMLEnvFileAndExtraFileAreGiven_ShouldMergeThemInOrder(t *testing.T) {
+	//Arrange
+	dir := t.TempDir()
+	writeFile(t, dir, environment.YAMLEnvFile, "staging:\n  host: http://staging\n  port: 8080\n")
+	extra := writeFile(t, t.TempDir(), "ci.env.yaml", "staging:\n  port: 9090\n")
+
+	//Act
+	vars, err := environment.Load(dir, "staging", extra)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "http://staging", "port": "9090"}, vars)
+}
+
+func Test_WhenEnvironmentDoesNotExist_ShouldError(t *testing.T) {
+	//Arrange
+	dir := t.TempDir()
+	writeFile(t, dir, environment.PublicEnvFile, `{"dev": {"host": "http://dev"}}`)
+
+	//Act
+	_, err := environment.Load(dir, "prod")
+
+	//Assert
+	assert.ErrorContains(t, err, `environment "prod" not found`)
+}
+
+func Test_WhenNoEnvironmentIsChosen_ShouldReturnNoVariables(t *testing.T) {
+	//Arrange
+	dir := t.TempDir()
+
+	//Act
+	vars, err := environment.Load(dir, "")
+
+	//Assert
+	require.NoError(t, err)
+	assert.Empty(t, vars)
+}
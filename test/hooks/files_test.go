@@ -0,0 +1,161 @@
+package hooks_tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func writeHooksFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func describeAll(hooks hooks_logic.HooksFile) []string {
+	var described []string
+	for _, hook := range hooks {
+		described = append(described, hook.Describe())
+	}
+	return described
+}
+
+func Test_WhenHooksFileIsJSON_ShouldLoadIt(t *testing.T) {
+	//Arrange
+	dir := writeHooksFiles(t, map[string]string{
+		"hooks.json": `[{"path": "/users", "method": "POST", "body": {"age": 42, "tags": ["new"]}, "response": {"status": [201], "maxLatency": "2s"}}]`,
+	})
+
+	//Act
+	hooks, err := hooks_logic.UnmarshalHooksFile(filepath.Join(dir, "hooks.json"))
+
+	//Assert
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	assert.Equal(t, map[string]any{"age": 42, "tags": []any{"new"}}, hooks[0].Body)
+	assert.Equal(t, []int{201}, hooks[0].Response.Status)
+}
+
+func Test_WhenHooksFileIsInvalidJSON_ShouldError(t *testing.T) {
+	//Arrange
+	dir := writeHooksFiles(t, map[string]string{"hooks.json": `[{"path": "/users",}]`})
+
+	//Act
+	_, err := hooks_logic.UnmarshalHooksFile(filepath.Join(dir, "hooks.json"))
+
+	//Assert
+	assert.ErrorContains(t, err, "invalid JSON")
+}
+
+func Test_WhenHooksIsADirectory_ShouldMergeEveryHooksFileInNameOrder(t *testing.T) {
+	//Arrange
+	dir := writeHooksFiles(t, map[string]string{
+		"b-orders.yml":        "- path: /orders\n",
+		"a-users.json":        `[{"path": "/users"}]`,
+		"payments/refund.yml": "- path: /refunds\n",
+		"README.md":           "not hooks",
+	})
+
+	//Act
+	hooks, err := hooks_logic.UnmarshalHooksFile(dir)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"* /users", "* /orders", "* /refunds"}, describeAll(hooks))
+}
+
+func Test_WhenHooksIncludeOtherFiles_ShouldReplaceTheIncludeWithTheirHooks(t *testing.T) {
+	//Arrange
+	dir := writeHooksFiles(t, map[string]string{
+		"hooks.yml":            "- path: /first\n- include: shared/auth.yml\n- path: /last\n",
+		"shared/auth.yml":      "- path: /login\n  body:\n    user: '${file(\"user.txt\")}'\n- include: ../more/\n",
+		"shared/user.txt":      "theo",
+		"more/extra.json":      `[{"path": "/extra"}]`,
+		"more/nested/deep.yml": "- path: /deep\n",
+	})
+
+	//Act
+	hooks, err := hooks_logic.UnmarshalHooksFile(filepath.Join(dir, "hooks.yml"))
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"* /first", "* /login", "* /extra", "* /deep", "* /last"}, describeAll(hooks))
+
+	evaluated, err := hooks_logic.EvaluateHookFunctions(hooks[1])
+	require.NoError(t, err)
+	assert.Equal(t, "theo", evaluated.Body["user"])
+}
+
+func Test_WhenHooksIncludeThemselves_ShouldError(t *testing.T) {
+	//Arrange
+	dir := writeHooksFiles(t, map[string]string{
+		"a.yml": "- include: b.yml\n",
+		"b.yml": "- include: a.yml\n",
+	})
+
+	//Act
+	_, err := hooks_logic.UnmarshalHooksFile(filepath.Join(dir, "a.yml"))
+
+	//Assert
+	assert.ErrorContains(t, err, "includes itself")
+}
+
+func Test_WhenIncludeHasOtherKeys_ShouldError(t *testing.T) {
+	//Arrange
+	dir := writeHooksFiles(t, map[string]string{
+		"hooks.yml": "- include: other.yml\n  path: /users\n",
+		"other.yml": "- path: /orders\n",
+	})
+
+	//Act
+	_, err := hooks_logic.UnmarshalHooksFile(filepath.Join(dir, "hooks.yml"))
+
+	//Assert
+	assert.ErrorContains(t, err, "include cannot be combined with other keys")
+}
+
+func Test_JSONSchema_ShouldDescribeEveryHookKey(t *testing.T) {
+	//Arrange
+	var schema struct {
+		Definitions map[string]struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"definitions"`
+	}
+
+	//Act
+	err := json.Unmarshal(hooks_logic.JSONSchema, &schema)
+
+	//Assert
+	require.NoError(t, err)
+	var documented []string
+	for key := range schema.Definitions["hook"].Properties {
+		documented = append(documented, key)
+	}
+	documented = append(documented, "include")
+	slices.Sort(documented)
+
+	var hookKeys []string
+	node := yaml.Node{}
+	require.NoError(t, node.Encode(hooks_logic.HookEntry{Path: "/", PathRegex: "x", OperationId: "x", Tag: "x", Method: "x",
+		Body: map[string]any{"a": 1}, Remove: []string{"a"}, Headers: map[string]string{"a": "b"}, Query: map[string]string{"a": "b"},
+		PathParams: map[string]string{"a": "b"}, Cookies: map[string]string{"a": "b"}, Response: &hooks_logic.ResponseHook{}, Include: "x"}))
+	for i := 0; i < len(node.Content); i += 2 {
+		hookKeys = append(hookKeys, node.Content[i].Value)
+	}
+	slices.Sort(hookKeys)
+
+	assert.Equal(t, hookKeys, documented)
+}
@@ -0,0 +1,92 @@
+package hooks_tests
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenHookValuesCallFunctions_ShouldEvaluateThem(t *testing.T) {
+	//Arrange
+	t.Setenv("API_TENANT", "acme")
+	hook := hooks_logic.HookEntry{
+		Body: map[string]any{
+			"id":      "${uuid()}",
+			"date":    `${now("2006-01-02")}`,
+			"count":   "${randomInt(1, 3)}",
+			"email":   "${faker.email()}",
+			"ref":     "order-${randomInt(5,5)}-${env('API_TENANT')}",
+			"literal": "$${uuid()}",
+			"nested":  map[string]any{"tags": []any{"${env(\"API_TENANT\")}"}},
+		},
+		Headers: map[string]string{"X-Attempt": "${randomInt(7, 7)}", "X-Region": `${env("UNSET_REGION", "eu")}`},
+	}
+
+	//Act
+	evaluated, err := hooks_logic.EvaluateHookFunctions(hook)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), evaluated.Body["id"])
+	assert.Equal(t, time.Now().UTC().Format("2006-01-02"), evaluated.Body["date"])
+	assert.Contains(t, []any{int64(1), int64(2), int64(3)}, evaluated.Body["count"])
+	assert.Regexp(t, `^[a-z]+\.[a-z]+\d+@example\.com$`, evaluated.Body["email"])
+	assert.Equal(t, "order-5-acme", evaluated.Body["ref"])
+	assert.Equal(t, "${uuid()}", evaluated.Body["literal"])
+	assert.Equal(t, map[string]any{"tags": []any{"acme"}}, evaluated.Body["nested"])
+	assert.Equal(t, map[string]string{"X-Attempt": "7", "X-Region": "eu"}, evaluated.Headers)
+	assert.Equal(t, "${uuid()}", hook.Body["id"])
+}
+
+func Test_WhenHookReadsAJSONFile_ShouldSetItsContentRelativeToTheHooksFile(t *testing.T) {
+	//Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "payloads"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "payloads", "address.json"), []byte(`{"city": "Leeds"}`), 0644))
+	hooksPath := filepath.Join(dir, "hooks.yml")
+	require.NoError(t, os.WriteFile(hooksPath, []byte(`- path: /users
+  method: POST
+  body:
+    address: '${file("./payloads/address.json")}'
+`), 0644))
+
+	hooks, err := hooks_logic.UnmarshalHooksFile(hooksPath)
+	require.NoError(t, err)
+
+	//Act
+	evaluated, err := hooks_logic.EvaluateHookFunctions(hooks[0])
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"city": "Leeds"}, evaluated.Body["address"])
+}
+
+func Test_WhenHookFunctionIsInvalid_ShouldError(t *testing.T) {
+	cases := map[string]string{
+		"${nope()}":              "unknown function",
+		"${uuid(1)}":             "takes 0 argument(s)",
+		"${randomInt(\"a\", 2)}": "takes two numbers",
+		"${env(\"UNSET_VAR\")}":  "UNSET_VAR is not set",
+		"${uuid()":               "missing }",
+		"${faker.colour()}":      "unknown faker field",
+		"${file(\"missing\")}":   "failed to read",
+	}
+
+	for value, message := range cases {
+		//Arrange
+		hook := hooks_logic.HookEntry{Path: "/users", Body: map[string]any{"name": value}}
+
+		//Act
+		_, err := hooks_logic.EvaluateHookFunctions(hook)
+
+		//Assert
+		assert.ErrorContains(t, err, message, value)
+		assert.ErrorContains(t, err, "body field name", value)
+	}
+}
@@ -0,0 +1,158 @@
+package hooks_tests
+
+import (
+	"testing"
+
+	"github.com/alexplayer15/parmesan/errors"
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderBody = `{
+  "customer": {"name": "Alex", "tags": ["new"]},
+  "items": [
+    {"sku": "A1", "note": "gift"},
+    {"sku": "B2", "note": "gift"},
+    {"sku": "C3", "note": "gift"}
+  ],
+  "coupon": "SAVE10"
+}`
+
+func Test_WhenHookSetsAnArrayOrObject_ShouldReplaceIt(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Body: map[string]any{
+		"customer.tags": []any{"vip", "returning"},
+		"customer":      map[string]any{"name": "Theo", "tags": []any{}},
+	}}
+
+	//Act
+	body, err := hooks_logic.ModifyRequestBodyUsingHook(hook, orderBody)
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"customer": {"name": "Theo", "tags": ["vip", "returning"]},
+		"items": [{"sku": "A1", "note": "gift"}, {"sku": "B2", "note": "gift"}, {"sku": "C3", "note": "gift"}],
+		"coupon": "SAVE10"
+	}`, body)
+}
+
+func Test_WhenHookSetsAFieldThatIsNotInTheBody_ShouldAddIt(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Body: map[string]any{
+		"channel":               "web",
+		"shipping.address.city": "Leeds",
+	}}
+
+	//Act
+	body, err := hooks_logic.ModifyRequestBodyUsingHook(hook, `{"coupon": "SAVE10"}`)
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"coupon": "SAVE10", "channel": "web", "shipping": {"address": {"city": "Leeds"}}}`, body)
+}
+
+func Test_WhenHookRemovesFields_ShouldDeleteThem(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Remove: []string{"coupon", "items.note", "items[0]"}}
+
+	//Act
+	body, err := hooks_logic.ModifyRequestBodyUsingHook(hook, orderBody)
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"customer": {"name": "Alex", "tags": ["new"]},
+		"items": [{"sku": "B2"}, {"sku": "C3"}]
+	}`, body)
+}
+
+func Test_WhenHookKeyHasAnIndex_ShouldOnlyChangeThatElement(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Body: map[string]any{
+		"items[2].sku":     "Z9",
+		"customer.tags[0]": "vip",
+	}}
+
+	//Act
+	body, err := hooks_logic.ModifyRequestBodyUsingHook(hook, orderBody)
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"customer": {"name": "Alex", "tags": ["vip"]},
+		"items": [{"sku": "A1", "note": "gift"}, {"sku": "B2", "note": "gift"}, {"sku": "Z9", "note": "gift"}],
+		"coupon": "SAVE10"
+	}`, body)
+}
+
+func Test_WhenHookKeyHasNoIndex_ShouldChangeEveryElement(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Body: map[string]any{"items.note": "none"}}
+
+	//Act
+	body, err := hooks_logic.ModifyRequestBodyUsingHook(hook, orderBody)
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"customer": {"name": "Alex", "tags": ["new"]},
+		"items": [{"sku": "A1", "note": "none"}, {"sku": "B2", "note": "none"}, {"sku": "C3", "note": "none"}],
+		"coupon": "SAVE10"
+	}`, body)
+}
+
+func Test_WhenHookIndexIsOutOfRange_ShouldReturnAHookError(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Body: map[string]any{"items[5].sku": "Z9"}}
+
+	//Act
+	_, err := hooks_logic.ModifyRequestBodyUsingHook(hook, orderBody)
+
+	//Assert
+	var hookErr *errors.HookError
+	require.ErrorAs(t, err, &hookErr)
+	assert.Equal(t, errors.ErrCodeHookIndexOutOfRange, hookErr.Code)
+	assert.Equal(t, "items[5]", hookErr.HookField)
+}
+
+func Test_WhenHookRemovesAMissingField_ShouldReturnAHookError(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Remove: []string{"customer.age"}}
+
+	//Act
+	_, err := hooks_logic.ModifyRequestBodyUsingHook(hook, orderBody)
+
+	//Assert
+	var hookErr *errors.HookError
+	require.ErrorAs(t, err, &hookErr)
+	assert.Equal(t, errors.ErrCodeHookBodyFieldDoesNotExist, hookErr.Code)
+	assert.Equal(t, "customer.age", hookErr.HookField)
+}
+
+func Test_WhenHookReplacesAnArrayWithAString_ShouldError(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Body: map[string]any{"items": "none"}}
+
+	//Act
+	_, err := hooks_logic.ModifyRequestBodyUsingHook(hook, orderBody)
+
+	//Assert
+	assert.ErrorContains(t, err, "expected array")
+}
+
+func Test_WhenHookKeyIsMalformed_ShouldReturnAHookError(t *testing.T) {
+	for _, key := range []string{"items[x].sku", "items[1", "customer..name", "items[0]sku"} {
+		//Arrange
+		hook := hooks_logic.HookEntry{Body: map[string]any{key: "Z9"}}
+
+		//Act
+		_, err := hooks_logic.ModifyRequestBodyUsingHook(hook, orderBody)
+
+		//Assert
+		var hookErr *errors.HookError
+		require.ErrorAs(t, err, &hookErr, key)
+		assert.Equal(t, errors.ErrCodeHookInvalidKey, hookErr.Code, key)
+	}
+}
@@ -0,0 +1,135 @@
+package hooks_tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func lintPetHooks(t *testing.T, hooks string) []string {
+	t.Helper()
+
+	var oas oas_struct.OAS
+	require.NoError(t, yaml.Unmarshal([]byte(petsOAS), &oas))
+
+	hooksPath := filepath.Join(t.TempDir(), "hooks.yml")
+	require.NoError(t, os.WriteFile(hooksPath, []byte(hooks), 0644))
+
+	issues, err := hooks_logic.LintHooksFile(oas, hooksPath)
+	require.NoError(t, err)
+
+	var reported []string
+	for _, issue := range issues {
+		reported = append(reported, issue.String())
+	}
+	return reported
+}
+
+func Test_WhenHookValuesBreakTheSchema_ShouldReportEachAtItsLine(t *testing.T) {
+	//Arrange
+	hooks := `- path: /pets
+  method: post
+  body:
+    age: "three"
+    species: parrot
+    /tags/-: ${uuid()}
+  remove:
+    - colour
+`
+
+	//Act
+	issues := lintPetHooks(t, hooks)
+
+	//Assert
+	assert.Equal(t, []string{
+		"4:5: hook 1 (POST /pets): createPet: body field age: expected integer, got string",
+		"5:5: hook 1 (POST /pets): createPet: body field species: value parrot is not one of the allowed values [cat dog]",
+		"8:7: hook 1 (POST /pets): createPet: body field colour: field colour is not defined in the schema and additionalProperties is false",
+	}, issues)
+}
+
+func Test_WhenHookMethodOrKeyIsWrong_ShouldReportIt(t *testing.T) {
+	//Arrange
+	hooks := `- path: /pets
+  method: DELETE
+- operationId: createPet
+  bodyy:
+    name: Rex
+- tag: cats
+`
+
+	//Act
+	issues := lintPetHooks(t, hooks)
+
+	//Assert
+	assert.Equal(t, []string{
+		"2:11: hook 1 (DELETE /pets): none of the operations the hook selects use method DELETE",
+		`4:3: hook 2 (* operationId createPet): unknown key "bodyy", hooks can have path, pathRegex, operationId, tag, method, body, remove, headers, query, pathParams, cookies, script, response, include`,
+		"6:8: hook 3 (* tag cats): no operation is tagged cats",
+	}, issues)
+}
+
+func Test_WhenHooksUseAnchorsAndMergeKeys_ShouldReportWithoutPanicking(t *testing.T) {
+	//Arrange
+	hooks := `- &pets
+  path: /pets
+  method: post
+  headers:
+    X-Tenant: acme
+  body:
+    age: "three"
+- <<: *pets
+  remove: &removed
+    - colour
+- <<: *pets
+  method: post
+  remove: *removed
+- path: /missing
+  <<: {}
+- <<: {path: /gone}
+`
+
+	//Act
+	issues := lintPetHooks(t, hooks)
+
+	//Assert
+	assert.Equal(t, []string{
+		"7:5: hook 1 (POST /pets): createPet: body field age: expected integer, got string",
+		"5:5: hook 1 (POST /pets): createPet does not declare a header parameter X-Tenant",
+		"8:3: hook 2 (POST /pets): createPet: body field age: expected integer, got string",
+		"10:7: hook 2 (POST /pets): createPet: body field colour: field colour is not defined in the schema and additionalProperties is false",
+		"8:3: hook 2 (POST /pets): createPet does not declare a header parameter X-Tenant",
+		"11:3: hook 3 (POST /pets): createPet: body field age: expected integer, got string",
+		"10:7: hook 3 (POST /pets): createPet: body field colour: field colour is not defined in the schema and additionalProperties is false",
+		"11:3: hook 3 (POST /pets): createPet does not declare a header parameter X-Tenant",
+		"14:9: hook 4 (* /missing): path /missing matches no operation in the OAS",
+		"16:3: hook 5 (* /gone): path /gone matches no operation in the OAS",
+	}, issues)
+}
+
+func Test_WhenHookValueOnlyLooksDynamic_ShouldCheckItAsSendWould(t *testing.T) {
+	//Arrange
+	hooks := `- path: /pets
+  method: post
+  body:
+    age: "{{a{b}}"
+    weight: "$${price}"
+    species: "{{species}}"
+    owner: '${faker.name()}'
+`
+
+	//Act
+	issues := lintPetHooks(t, hooks)
+
+	//Assert
+	assert.Equal(t, []string{
+		"4:5: hook 1 (POST /pets): createPet: body field age: expected integer, got string",
+		"5:5: hook 1 (POST /pets): createPet: body field weight: expected number, got string",
+	}, issues)
+}
@@ -0,0 +1,124 @@
+package hooks_tests
+
+import (
+	"testing"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const matchServerURL = "http://localhost/api"
+
+var getUser = operations.Operation{
+	Path:    "/users/{id}",
+	Method:  "GET",
+	Details: oas_struct.Method{OperationId: "getUser", Tags: []string{"users"}},
+}
+
+func getUserRequest() request_sender.Request {
+	return request_sender.Request{Method: "GET", Url: matchServerURL + "/users/123"}
+}
+
+func Test_WhenHookPathIsATemplate_ShouldMatchFilledInPaths(t *testing.T) {
+	//Arrange
+	hooks := hooks_logic.HooksFile{{Path: "/users/{id}", Method: "GET"}}
+
+	//Act
+	withOperation := hooks_logic.FindHooksForRequest(hooks, getUserRequest(), getUser, matchServerURL)
+	withoutOperation := hooks_logic.FindHooksForRequest(hooks, getUserRequest(), operations.Operation{}, matchServerURL)
+
+	//Assert
+	assert.Len(t, withOperation, 1)
+	assert.Len(t, withoutOperation, 1)
+}
+
+func Test_WhenHookPathIncludesTheServerBasePath_ShouldStillMatch(t *testing.T) {
+	//Arrange
+	hooks := hooks_logic.HooksFile{{Path: "/api/users/{id}", Method: "GET"}}
+
+	//Act
+	matching := hooks_logic.FindHooksForRequest(hooks, getUserRequest(), getUser, matchServerURL)
+
+	//Assert
+	assert.Len(t, matching, 1)
+}
+
+func Test_WhenRequestPathOnlySharesAPrefixWithTheBasePath_ShouldNotStripIt(t *testing.T) {
+	//Arrange
+	hooks := hooks_logic.HooksFile{{Path: "/ary/users/{id}", Method: "GET"}}
+	req := request_sender.Request{Method: "GET", Url: "http://localhost/apiary/users/123"}
+
+	//Act
+	matching := hooks_logic.FindHooksForRequest(hooks, req, operations.Operation{}, matchServerURL)
+
+	//Assert
+	assert.Empty(t, matching)
+}
+
+func Test_WhenHookSelectsRequestsInOtherWays_ShouldMatchAccordingly(t *testing.T) {
+	cases := map[string]struct {
+		hook    hooks_logic.HookEntry
+		matches bool
+	}{
+		"glob with **":          {hooks_logic.HookEntry{Path: "/users/**"}, true},
+		"glob with *":           {hooks_logic.HookEntry{Path: "/*/123"}, true},
+		"glob too short":        {hooks_logic.HookEntry{Path: "/*"}, false},
+		"regex":                 {hooks_logic.HookEntry{PathRegex: `^/users/\d+$`}, true},
+		"regex not matching":    {hooks_logic.HookEntry{PathRegex: `^/orders/`}, false},
+		"operationId":           {hooks_logic.HookEntry{OperationId: "getUser"}, true},
+		"other operationId":     {hooks_logic.HookEntry{OperationId: "deleteUser"}, false},
+		"tag":                   {hooks_logic.HookEntry{Tag: "users"}, true},
+		"method wildcard":       {hooks_logic.HookEntry{Path: "/users/{id}", Method: "*"}, true},
+		"lower case method":     {hooks_logic.HookEntry{Path: "/users/{id}", Method: "get"}, true},
+		"other method":          {hooks_logic.HookEntry{Path: "/users/{id}", Method: "DELETE"}, false},
+		"global default":        {hooks_logic.HookEntry{}, true},
+		"tag and other method":  {hooks_logic.HookEntry{Tag: "users", Method: "POST"}, false},
+		"exact path mismatches": {hooks_logic.HookEntry{Path: "/users"}, false},
+	}
+
+	for name, testCase := range cases {
+		//Act
+		matching := hooks_logic.FindHooksForRequest(hooks_logic.HooksFile{testCase.hook}, getUserRequest(), getUser, matchServerURL)
+
+		//Assert
+		assert.Equal(t, testCase.matches, len(matching) == 1, name)
+	}
+}
+
+func Test_WhenSeveralHooksMatch_ShouldMergeThemFromGlobalToSpecific(t *testing.T) {
+	//Arrange
+	hooks := hooks_logic.HooksFile{
+		{OperationId: "getUser", Headers: map[string]string{"X-Source": "operation"}},
+		{Path: "/users/**", Headers: map[string]string{"X-Source": "glob", "X-Glob": "yes"}},
+		{Tag: "users", Headers: map[string]string{"X-Source": "tag", "X-Tag": "yes"}},
+		{Headers: map[string]string{"X-Source": "global", "X-Global": "yes"}, Remove: []string{"debug"}},
+	}
+
+	//Act
+	matching := hooks_logic.FindHooksForRequest(hooks, getUserRequest(), getUser, matchServerURL)
+	merged := hooks_logic.MergeHooks(matching)
+
+	//Assert
+	require.Len(t, matching, 4)
+	assert.Equal(t, "* all requests", matching[0].Describe())
+	assert.Equal(t, "* tag users", matching[1].Describe())
+	assert.Equal(t, "* /users/**", matching[2].Describe())
+	assert.Equal(t, "* operationId getUser", matching[3].Describe())
+	assert.Equal(t, map[string]string{"X-Source": "operation", "X-Glob": "yes", "X-Tag": "yes", "X-Global": "yes"}, merged.Headers)
+	assert.Equal(t, []string{"debug"}, merged.Remove)
+}
+
+func Test_WhenHooksFileHasAnInvalidRegex_ShouldFailValidation(t *testing.T) {
+	//Arrange
+	hooks := hooks_logic.HooksFile{{PathRegex: "^/users/(\\d+$"}}
+
+	//Act
+	err := hooks.Validate()
+
+	//Assert
+	assert.ErrorContains(t, err, "hook 1: invalid pathRegex")
+}
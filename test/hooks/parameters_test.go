@@ -0,0 +1,127 @@
+package hooks_tests
+
+import (
+	"testing"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/errors"
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var listOrders = operations.Operation{
+	Path:   "/users/{userId}/orders",
+	Method: "GET",
+	Details: oas_struct.Method{
+		OperationId: "listOrders",
+		Parameters: []oas_struct.Parameter{
+			{Name: "X-Tenant", In: "header"},
+			{Name: "status", In: "query"},
+			{Name: "session", In: "cookie"},
+		},
+	},
+}
+
+func Test_WhenHookSetsParameters_ShouldApplyThemToTheRequest(t *testing.T) {
+	//Arrange
+	req := request_sender.Request{
+		Method:  "GET",
+		Url:     "http://localhost/api/users/42/orders?status=open&limit=5",
+		Headers: map[string]string{"x-tenant": "acme", "Cookie": "theme=dark; session=old"},
+	}
+	hook := hooks_logic.HookEntry{
+		Headers:    map[string]string{"X-Tenant": "globex"},
+		Query:      map[string]string{"status": "shipped"},
+		PathParams: map[string]string{"userId": "a b"},
+		Cookies:    map[string]string{"session": "abc123"},
+	}
+
+	//Act
+	modified, err := hooks_logic.ModifyRequestParametersUsingHook(hook, req, listOrders.Path)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost/api/users/a%20b/orders?limit=5&status=shipped", modified.Url)
+	assert.Equal(t, map[string]string{"X-Tenant": "globex", "Cookie": "theme=dark; session=abc123"}, modified.Headers)
+	assert.Equal(t, "acme", req.Headers["x-tenant"])
+}
+
+func Test_WhenNoPathTemplateIsKnown_ShouldFillPathParamPlaceholders(t *testing.T) {
+	//Arrange
+	req := request_sender.Request{Method: "GET", Url: "http://localhost/users/{userId}/orders"}
+	hook := hooks_logic.HookEntry{PathParams: map[string]string{"userId": "7"}}
+
+	//Act
+	modified, err := hooks_logic.ModifyRequestParametersUsingHook(hook, req, "")
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost/users/7/orders", modified.Url)
+}
+
+func Test_WhenHookParametersAreDeclared_ShouldPassValidation(t *testing.T) {
+	//Arrange
+	req := request_sender.Request{Headers: map[string]string{"Content-Type": "application/json"}}
+	hook := hooks_logic.HookEntry{
+		Headers:    map[string]string{"x-tenant": "acme", "Authorization": "Bearer t", "content-type": "text/plain"},
+		Query:      map[string]string{"status": "open"},
+		PathParams: map[string]string{"userId": "7"},
+		Cookies:    map[string]string{"session": "abc"},
+	}
+
+	//Act
+	err := hooks_logic.ValidateHookParameters(hook, listOrders, req)
+
+	//Assert
+	assert.NoError(t, err)
+}
+
+func Test_WhenHookParameterIsNotDeclared_ShouldReturnAHookError(t *testing.T) {
+	cases := map[string]hooks_logic.HookEntry{
+		"headers.X-Region": {Headers: map[string]string{"X-Region": "eu"}},
+		"query.sort":       {Query: map[string]string{"sort": "asc"}},
+		"pathParams.id":    {PathParams: map[string]string{"id": "7"}},
+		"cookies.theme":    {Cookies: map[string]string{"theme": "dark"}},
+	}
+
+	for field, hook := range cases {
+		//Act
+		err := hooks_logic.ValidateHookParameters(hook, listOrders, request_sender.Request{})
+
+		//Assert
+		var hookErr *errors.HookError
+		require.ErrorAs(t, err, &hookErr, field)
+		assert.Equal(t, errors.ErrCodeHookParameterDoesNotExist, hookErr.Code)
+		assert.Equal(t, field, hookErr.HookField)
+		assert.Contains(t, err.Error(), "listOrders")
+	}
+}
+
+func Test_WhenURLHasAQueryButNoPath_ShouldKeepTheQueryAfterThePath(t *testing.T) {
+	//Arrange
+	req := request_sender.Request{Method: "GET", Url: "http://localhost:8080?x=1#top"}
+	hook := hooks_logic.HookEntry{PathParams: map[string]string{"tenant": "acme"}}
+
+	//Act
+	modified, err := hooks_logic.ModifyRequestParametersUsingHook(hook, req, "/{tenant}")
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/acme?x=1#top", modified.Url)
+}
+
+func Test_WhenURLHasATrailingSlash_ShouldFillThePathParamAndKeepTheSlash(t *testing.T) {
+	//Arrange
+	req := request_sender.Request{Method: "GET", Url: "http://localhost/users/users/42/orders/?status=open"}
+	hook := hooks_logic.HookEntry{PathParams: map[string]string{"userId": "7"}}
+
+	//Act
+	modified, err := hooks_logic.ModifyRequestParametersUsingHook(hook, req, listOrders.Path)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost/users/users/7/orders/?status=open", modified.Url)
+}
@@ -0,0 +1,84 @@
+package hooks_tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/stretchr/testify/assert"
+)
+
+var orderResponse = request_sender.Response{
+	StatusCode: 201,
+	Headers:    http.Header{"Location": []string{"/orders/7"}},
+	Body:       `{"id": 7, "status": "placed", "tags": ["vip", "new"], "customer": {"name": "Alex", "tier": 2}}`,
+	Timing:     request_sender.Timing{Total: 120 * time.Millisecond},
+}
+
+func Test_WhenResponseMeetsEveryAssertion_ShouldReturnNoFailures(t *testing.T) {
+	//Arrange
+	hook := &hooks_logic.ResponseHook{
+		Status:     []int{200, 201},
+		Headers:    []string{"location"},
+		MaxLatency: time.Second,
+		Body: []hooks_logic.BodyAssertion{
+			{Path: "$.id", Equals: 7},
+			{Path: "$.tags", Contains: "vip"},
+			{Path: "$.customer", Contains: map[string]any{"tier": 2}},
+			{Path: "$.status", Contains: "lace", Matches: "^[a-z]+$"},
+		},
+	}
+
+	//Act
+	failures := hook.Check(orderResponse)
+
+	//Assert
+	assert.Empty(t, failures)
+	assert.Equal(t, 7, hook.Assertions())
+}
+
+func Test_WhenResponseFailsAssertions_ShouldDescribeEachFailure(t *testing.T) {
+	//Arrange
+	hook := &hooks_logic.ResponseHook{
+		Status:     []int{200},
+		Headers:    []string{"X-Request-Id"},
+		MaxLatency: 100 * time.Millisecond,
+		Body: []hooks_logic.BodyAssertion{
+			{Path: "$.customer.name", Equals: "Sam"},
+			{Path: "$.tags", Contains: "old"},
+			{Path: "$.missing", Equals: 1},
+		},
+	}
+
+	//Act
+	failures := hook.Check(orderResponse)
+
+	//Assert
+	assert.Equal(t, []string{
+		"status 201 is not one of [200]",
+		"header X-Request-Id is missing",
+		"took 120ms, over the 100ms limit",
+		`$.customer.name: expected "Sam", got "Alex"`,
+		`$.tags: ["vip","new"] does not contain "old"`,
+		"$.missing: matched nothing in the response body",
+	}, failures)
+}
+
+func Test_WhenResponseHooksAreMerged_ShouldOverrideStatusAndCollectAssertions(t *testing.T) {
+	//Arrange
+	hooks := []hooks_logic.HookEntry{
+		{Response: &hooks_logic.ResponseHook{Status: []int{200}, Body: []hooks_logic.BodyAssertion{{Path: "$.id", Equals: 1}}, Capture: map[string]string{"a": "$.id"}}},
+		{Path: "/orders", Response: &hooks_logic.ResponseHook{Status: []int{201}, Headers: []string{"Location"}, Capture: map[string]string{"b": "header.Location"}}},
+	}
+
+	//Act
+	merged := hooks_logic.MergeHooks(hooks)
+
+	//Assert
+	assert.Equal(t, []int{201}, merged.Response.Status)
+	assert.Len(t, merged.Response.Body, 1)
+	assert.Equal(t, []string{"Location"}, merged.Response.Headers)
+	assert.Equal(t, map[string]string{"a": "$.id", "b": "header.Location"}, merged.Response.Capture)
+}
@@ -0,0 +1,136 @@
+package hooks_tests
+
+import (
+	"testing"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/errors"
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const petsOAS = `
+openapi: 3.0.0
+info:
+  title: Pets API
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        '201':
+          description: Created
+components:
+  schemas:
+    Pet:
+      type: object
+      additionalProperties: false
+      properties:
+        name:
+          type: string
+          minLength: 1
+          pattern: '^[A-Z]'
+        age:
+          type: integer
+          minimum: 0
+          maximum: 30
+        weight:
+          type: number
+          exclusiveMinimum: true
+          minimum: 0
+        species:
+          type: string
+          enum: [cat, dog]
+        owner:
+          type: string
+          nullable: true
+        tags:
+          type: array
+          items:
+            type: string
+            format: uuid
+`
+
+const petBody = `{"name": "Rex", "age": 3, "weight": 12.5, "species": "dog", "owner": "Sam", "tags": []}`
+
+func modifyPet(t *testing.T, body map[string]any) (string, error) {
+	t.Helper()
+
+	var oas oas_struct.OAS
+	require.NoError(t, yaml.Unmarshal([]byte(petsOAS), &oas))
+	op, ok := operations.FindByOperationId(oas, "createPet")
+	require.True(t, ok)
+	schema, ok := hooks_logic.RequestBodySchema(op)
+	require.True(t, ok)
+
+	return hooks_logic.ModifyRequestBodyUsingSchema(hooks_logic.HookEntry{Body: body}, petBody, oas, schema)
+}
+
+func Test_WhenHookValuesMatchTheSchema_ShouldModifyBody(t *testing.T) {
+	//Arrange
+	body := map[string]any{
+		"age":     7,
+		"weight":  4,
+		"species": "cat",
+		"owner":   nil,
+		"/tags/-": "0b0b4c3e-7c1a-4a8e-9d4e-1d2f3a4b5c6d",
+	}
+
+	//Act
+	modified, err := modifyPet(t, body)
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "Rex", "age": 7, "weight": 4, "species": "cat", "owner": null,
+		"tags": ["0b0b4c3e-7c1a-4a8e-9d4e-1d2f3a4b5c6d"]
+	}`, modified)
+}
+
+func Test_WhenHookValueBreaksTheSchema_ShouldReturnAHookErrorWithTheSchemaPath(t *testing.T) {
+	tests := []struct {
+		field string
+		value any
+		msg   string
+	}{
+		{"age", 2.5, "expected integer"},
+		{"age", 31, "maximum"},
+		{"weight", 0, "minimum"},
+		{"species", "parrot", "not one of the allowed values"},
+		{"name", nil, "expected string, got null"},
+		{"name", "rex", "pattern"},
+		{"/tags/-", "not-a-uuid", "uuid"},
+		{"colour", "brown", "additionalProperties is false"},
+	}
+
+	for _, test := range tests {
+		//Act
+		_, err := modifyPet(t, map[string]any{test.field: test.value})
+
+		//Assert
+		var hookErr *errors.HookError
+		require.ErrorAs(t, err, &hookErr, test.field)
+		assert.Equal(t, errors.ErrCodeHookValueInvalid, hookErr.Code, test.field)
+		assert.ErrorContains(t, err, test.msg, test.field)
+	}
+}
+
+func Test_WhenThereIsNoSchema_ShouldAcceptAnIntegerForANumber(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Body: map[string]any{"total": 10}}
+
+	//Act
+	body, err := hooks_logic.ModifyRequestBodyUsingHook(hook, `{"total": 9.99}`)
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"total": 10}`, body)
+}
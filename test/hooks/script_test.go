@@ -0,0 +1,44 @@
+package hooks_tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/script"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenHookScriptIsAStarFile_ShouldReadItRelativeToTheHooksFile(t *testing.T) {
+	//Arrange
+	dir := writeHooksFiles(t, map[string]string{
+		"team/hooks.yml":         "- path: /users\n  script: scripts/sign.star\n",
+		"team/scripts/sign.star": `request["headers"]["X-Signature"] = hash.hmac_sha256("key", request["body"])`,
+	})
+	hooks, err := hooks_logic.UnmarshalHooksFile(filepath.Join(dir, "team", "hooks.yml"))
+	require.NoError(t, err)
+
+	//Act
+	requestScript, err := hooks[0].RequestScript()
+	require.NoError(t, err)
+	req, err := requestScript.BeforeRequest(request_sender.Request{Method: "POST", Url: "http://localhost/users", Headers: map[string]string{}, Body: "{}"}, script.Env{})
+
+	//Assert
+	require.NoError(t, err)
+	assert.Len(t, req.Headers["X-Signature"], 64)
+}
+
+func Test_WhenHookScriptDoesNotCompile_ShouldErrorWhenLoading(t *testing.T) {
+	//Arrange
+	dir := writeHooksFiles(t, map[string]string{
+		"hooks.yml": "- path: /users\n  response:\n    script: set_var(\"id\",\n",
+	})
+
+	//Act
+	_, err := hooks_logic.UnmarshalHooksFile(filepath.Join(dir, "hooks.yml"))
+
+	//Assert
+	assert.ErrorContains(t, err, "hook 1: response invalid script")
+}
@@ -0,0 +1,93 @@
+package hooks_tests
+
+import (
+	"testing"
+
+	"github.com/alexplayer15/parmesan/errors"
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const contactBody = `{
+  "a.b": "dotted",
+  "phones": [
+    {"type": "home", "value": "0111"},
+    {"type": "primary", "value": "0222"},
+    {"type": "work", "value": "0333"}
+  ]
+}`
+
+func Test_WhenHookKeyIsAJSONPointer_ShouldAddressKeysWithDotsAndArrayIndices(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Body: map[string]any{
+		"/a.b":           "changed",
+		"/phones/0/type": "mobile",
+		"/phones/-":      map[string]any{"type": "fax", "value": "0444"},
+	}}
+
+	//Act
+	body, err := hooks_logic.ModifyRequestBodyUsingHook(hook, contactBody)
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"a.b": "changed",
+		"phones": [
+			{"type": "mobile", "value": "0111"},
+			{"type": "primary", "value": "0222"},
+			{"type": "work", "value": "0333"},
+			{"type": "fax", "value": "0444"}
+		]
+	}`, body)
+}
+
+func Test_WhenHookKeyIsAJSONPathFilter_ShouldChangeEveryMatch(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{
+		Body:   map[string]any{"$.phones[?(@.type=='primary')].value": "0999"},
+		Remove: []string{"$.phones[?(@.type != 'primary')]", "$['a.b']"},
+	}
+
+	//Act
+	body, err := hooks_logic.ModifyRequestBodyUsingHook(hook, contactBody)
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"phones": [{"type": "primary", "value": "0999"}]}`, body)
+}
+
+func Test_WhenJSONPathMatchesNothing_ShouldReturnAHookError(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Body: map[string]any{"$.phones[?(@.type=='pager')].value": "0999"}}
+
+	//Act
+	_, err := hooks_logic.ModifyRequestBodyUsingHook(hook, contactBody)
+
+	//Assert
+	var hookErr *errors.HookError
+	require.ErrorAs(t, err, &hookErr)
+	assert.Equal(t, errors.ErrCodeHookSelectorMatchedNone, hookErr.Code)
+	assert.Equal(t, "$.phones[?(@.type=='pager')].value", hookErr.HookField)
+}
+
+func Test_WhenJSONPointerIsOutOfRangeOrInvalid_ShouldReturnAHookError(t *testing.T) {
+	cases := map[string]errors.ErrorCode{
+		"/phones/7/type":        errors.ErrCodeHookIndexOutOfRange,
+		"/phones/x/type":        errors.ErrCodeHookInvalidKey,
+		"$.phones[?(@.type==)]": errors.ErrCodeHookInvalidKey,
+	}
+
+	for key, code := range cases {
+		//Arrange
+		hook := hooks_logic.HookEntry{Body: map[string]any{key: "mobile"}}
+
+		//Act
+		_, err := hooks_logic.ModifyRequestBodyUsingHook(hook, contactBody)
+
+		//Assert
+		var hookErr *errors.HookError
+		require.ErrorAs(t, err, &hookErr, key)
+		assert.Equal(t, code, hookErr.Code, key)
+	}
+}
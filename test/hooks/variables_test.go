@@ -0,0 +1,46 @@
+package hooks_tests
+
+import (
+	"testing"
+
+	"github.com/alexplayer15/parmesan/errors"
+	hooks_logic "github.com/alexplayer15/parmesan/hooks"
+	"github.com/alexplayer15/parmesan/variables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenHookValueIsAVariableForATypedField_ShouldCheckTheResolvedValue(t *testing.T) {
+	//Arrange
+	typed := map[string]any{"petAge": int64(5)}
+	lookup := variables.FromMap(map[string]string{"petAge": "5", "owner": "Sam"})
+	hook := hooks_logic.HookEntry{
+		Body:    map[string]any{"age": "{{ petAge }}", "owner": "{{owner}}"},
+		Headers: map[string]string{"X-Owner": "{{owner}}"},
+	}
+
+	//Act
+	resolved, err := hooks_logic.ResolveHookVariables(hook, typed, lookup)
+	require.NoError(t, err)
+	modified, err := modifyPet(t, resolved.Body)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Sam", resolved.Headers["X-Owner"])
+	assert.JSONEq(t, `{"name": "Rex", "age": 5, "weight": 12.5, "species": "dog", "owner": "Sam", "tags": []}`, modified)
+	assert.Equal(t, "{{ petAge }}", hook.Body["age"])
+}
+
+func Test_WhenHookVariableIsUnknown_ShouldReturnAnUnresolvedVariableError(t *testing.T) {
+	//Arrange
+	hook := hooks_logic.HookEntry{Body: map[string]any{"age": "{{petAge}}"}}
+
+	//Act
+	_, err := hooks_logic.ResolveHookVariables(hook, nil, variables.FromMap(nil))
+
+	//Assert
+	var validationErr *errors.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, errors.ErrCodeUnresolvedVar, validationErr.Code)
+	assert.ErrorContains(t, err, "body field age")
+}
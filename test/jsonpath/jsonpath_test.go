@@ -0,0 +1,106 @@
+package jsonpath_tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/jsonpath"
+	"github.com/alexplayer15/parmesan/jsonpointer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const document = `{
+	"id": 7,
+	"a.b": "dotted",
+	"items": [
+		{"sku": "A1", "tags": ["new"]},
+		{"sku": "B2", "tags": []}
+	],
+	"owner": {"sku": "Z9"}
+}`
+
+func decode(t *testing.T) any {
+	t.Helper()
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(document), &doc))
+	return doc
+}
+
+func Test_WhenQueryingWithJSONPath_ShouldSelectMatchingValues(t *testing.T) {
+	cases := map[string][]any{
+		"$.id":            {float64(7)},
+		"$['a.b']":        {"dotted"},
+		"$.items[1].sku":  {"B2"},
+		"$.items[-1].sku": {"B2"},
+		"$.items[*].sku":  {"A1", "B2"},
+		"$..sku":          {"A1", "B2", "Z9"},
+		"$.missing":       nil,
+
+		"$.items[?(@.sku=='B2')].sku":                 {"B2"},
+		"$.items[?(@.sku != \"B2\")].sku":             {"A1"},
+		"$.items[?(@.tags[0])].sku":                   {"A1"},
+		"$.items[?(!@.tags[0])].sku":                  {"B2"},
+		"$.items[?@.sku > 'A9' || @.sku == 'A1'].sku": {"A1", "B2"},
+		"$.items[?(@.sku=='A1' && $.id >= 7)].sku":    {"A1"},
+		"$.items[?(@.sku=='A1' && $.id < 7)].sku":     nil,
+		"$..[?(@.sku=='Z9')].sku":                     {"Z9"},
+	}
+
+	for expr, expected := range cases {
+		t.Run(expr, func(t *testing.T) {
+			//Act
+			matches, err := jsonpath.Query(decode(t), expr)
+
+			//Assert
+			require.NoError(t, err)
+			assert.Equal(t, expected, matches)
+		})
+	}
+}
+
+func Test_WhenJSONPathIsInvalid_ShouldError(t *testing.T) {
+	for _, expr := range []string{"id", "$.items[", "$.items[?bad]", "$.", "$.items[?('a')]", "$.items[?(@.sku=='A1']", "$.items[?(@.sku==)]"} {
+		//Act
+		_, err := jsonpath.Query(decode(t), expr)
+
+		//Assert
+		assert.Error(t, err, expr)
+	}
+}
+
+func Test_WhenQueryingPointers_ShouldReturnWhereEachMatchIs(t *testing.T) {
+	//Act
+	pointers, err := jsonpath.QueryPointers(decode(t), "$..[?(@.sku)]")
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/owner", "/items/0", "/items/1"}, pointers)
+}
+
+func Test_WhenGettingWithJSONPointer_ShouldFollowEscapedTokens(t *testing.T) {
+	//Arrange
+	doc := decode(t)
+
+	//Act
+	sku, err := jsonpointer.Get(doc, "/items/0/sku")
+	dotted, dottedErr := jsonpointer.Get(doc, "/a.b")
+	_, missingErr := jsonpointer.Get(doc, "/items/5")
+
+	//Assert
+	require.NoError(t, err)
+	require.NoError(t, dottedErr)
+	assert.Equal(t, "A1", sku)
+	assert.Equal(t, "dotted", dotted)
+	assert.ErrorContains(t, missingErr, "out of range")
+}
+
+func Test_WhenPointerTokensContainSlashOrTilde_ShouldRoundTrip(t *testing.T) {
+	//Act
+	tokens, err := jsonpointer.Parse("/a~1b/c~0d")
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a/b", "c~d"}, tokens)
+	assert.Equal(t, "/a~1b/c~0d", jsonpointer.Format(tokens))
+}
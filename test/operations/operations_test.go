@@ -0,0 +1,51 @@
+package operations_tests
+
+import (
+	"testing"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenPathOnlySharesAPrefixWithTheBasePath_ShouldNotStripIt(t *testing.T) {
+	//Arrange
+	oas := oas_struct.OAS{Paths: map[string]map[string]oas_struct.Method{
+		"/apiary":     {"get": {OperationId: "getApiary"}},
+		"/users/{id}": {"get": {OperationId: "getUser"}},
+	}}
+
+	//Act
+	apiary, apiaryFound := operations.FindOperation(oas, "http://localhost/api", "GET", "http://localhost/apiary")
+	user, userFound := operations.FindOperation(oas, "http://localhost/api/", "GET", "http://localhost/api/users/7")
+
+	//Assert
+	require.True(t, apiaryFound)
+	assert.Equal(t, "getApiary", apiary.Name())
+	require.True(t, userFound)
+	assert.Equal(t, map[string]string{"id": "7"}, user.PathParams("http://localhost/api/", "http://localhost/api/users/7"))
+}
+
+func Test_WhenTrimmingABasePath_ShouldOnlyStripWholeSegments(t *testing.T) {
+	tests := []struct {
+		path     string
+		basePath string
+		want     string
+		ok       bool
+	}{
+		{"/api/users", "/api", "/users", true},
+		{"/api", "/api/", "", true},
+		{"/apiary", "/api", "/apiary", false},
+		{"/users", "", "/users", true},
+	}
+
+	for _, test := range tests {
+		//Act
+		got, ok := operations.TrimBasePath(test.path, test.basePath)
+
+		//Assert
+		assert.Equal(t, test.want, got, test.path)
+		assert.Equal(t, test.ok, ok, test.path)
+	}
+}
@@ -0,0 +1,180 @@
+package output_tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexplayer15/parmesan/output"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleResult(operation string, status int) results.Result {
+	return results.Result{
+		Request: request_sender.Request{
+			Method:  "POST",
+			Url:     "http://localhost:8080/users?dryRun=true",
+			Headers: map[string]string{"Content-Type": "application/json"},
+			Body:    `{"name": "Alex"}`,
+		},
+		Response: request_sender.Response{
+			StatusCode:    status,
+			Headers:       http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+			Body:          `{"id": 1}`,
+			Proto:         "HTTP/1.1",
+			ContentLength: 9,
+			Timing:        request_sender.Timing{Start: time.Now(), TTFB: 5 * time.Millisecond, Total: 6 * time.Millisecond},
+		},
+		Operation: operation,
+	}
+}
+
+func writeAll(t *testing.T, formats []string, rs ...results.Result) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	writer, err := output.NewResponseWriter(formats, dir, "oas")
+	require.NoError(t, err)
+	for _, r := range rs {
+		require.NoError(t, writer.Write(r))
+	}
+	require.NoError(t, writer.Close())
+
+	return dir
+}
+
+func Test_WhenWritingJSON_ShouldWriteAValidArrayOfSavedResponses(t *testing.T) {
+	//Arrange & Act
+	dir := writeAll(t, []string{output.FormatJSON}, sampleResult("createUser", 201), sampleResult("createUser", 400))
+
+	//Assert
+	content, err := os.ReadFile(filepath.Join(dir, "oas.json"))
+	require.NoError(t, err)
+	var saved []results.SavedResponse
+	require.NoError(t, json.Unmarshal(content, &saved))
+	assert.Len(t, saved, 2)
+	assert.Equal(t, 400, saved[1].Status)
+}
+
+func Test_WhenNothingIsWrittenToJSON_ShouldWriteAnEmptyArray(t *testing.T) {
+	//Arrange & Act
+	dir := writeAll(t, []string{output.FormatJSON})
+
+	//Assert
+	content, err := os.ReadFile(filepath.Join(dir, "oas.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(content))
+}
+
+func Test_WhenWritingNDJSON_ShouldWriteOneRecordPerLine(t *testing.T) {
+	//Arrange & Act
+	dir := writeAll(t, []string{output.FormatNDJSON}, sampleResult("createUser", 201), sampleResult("listUsers", 200))
+
+	//Assert
+	content, err := os.ReadFile(filepath.Join(dir, "oas.ndjson"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	var saved results.SavedResponse
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &saved))
+	assert.Equal(t, "listUsers", saved.Operation)
+}
+
+func Test_WhenWritingHAR_ShouldWriteAHAR12Log(t *testing.T) {
+	//Arrange & Act
+	dir := writeAll(t, []string{output.FormatHAR}, sampleResult("createUser", 201))
+
+	//Assert
+	content, err := os.ReadFile(filepath.Join(dir, "oas.har"))
+	require.NoError(t, err)
+
+	var har struct {
+		Log struct {
+			Version string `json:"version"`
+			Entries []struct {
+				Request struct {
+					Method      string `json:"method"`
+					QueryString []struct {
+						Name  string `json:"name"`
+						Value string `json:"value"`
+					} `json:"queryString"`
+					PostData struct {
+						MimeType string `json:"mimeType"`
+					} `json:"postData"`
+				} `json:"request"`
+				Response struct {
+					Status  int `json:"status"`
+					Content struct {
+						MimeType string `json:"mimeType"`
+						Text     string `json:"text"`
+					} `json:"content"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	require.NoError(t, json.Unmarshal(content, &har))
+	assert.Equal(t, "1.2", har.Log.Version)
+	require.Len(t, har.Log.Entries, 1)
+
+	entry := har.Log.Entries[0]
+	assert.Equal(t, "POST", entry.Request.Method)
+	assert.Equal(t, "dryRun", entry.Request.QueryString[0].Name)
+	assert.Equal(t, "application/json", entry.Request.PostData.MimeType)
+	assert.Equal(t, 201, entry.Response.Status)
+	assert.Equal(t, "application/json", entry.Response.Content.MimeType)
+	assert.Equal(t, `{"id": 1}`, entry.Response.Content.Text)
+}
+
+func Test_WhenARequestCouldNotBeSent_ShouldWriteItsErrorToHAR(t *testing.T) {
+	//Arrange
+	failed := sampleResult("createUser", 0)
+	failed.Response = request_sender.Response{Timing: request_sender.Timing{Start: time.Now()}}
+	failed.Err = errors.New("connection refused")
+
+	//Act
+	dir := writeAll(t, []string{output.FormatHAR}, failed)
+
+	//Assert
+	content, err := os.ReadFile(filepath.Join(dir, "oas.har"))
+	require.NoError(t, err)
+
+	var har struct {
+		Log struct {
+			Entries []struct {
+				Response struct {
+					Status int    `json:"status"`
+					Error  string `json:"_error"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	require.NoError(t, json.Unmarshal(content, &har))
+	require.Len(t, har.Log.Entries, 1)
+	assert.Equal(t, 0, har.Log.Entries[0].Response.Status)
+	assert.Equal(t, "connection refused", har.Log.Entries[0].Response.Error)
+}
+
+func Test_WhenWritingPerOperation_ShouldNameFilesByOperationId(t *testing.T) {
+	//Arrange & Act
+	dir := writeAll(t, []string{output.FormatPerOperation}, sampleResult("createUser", 201), sampleResult("createUser", 201))
+
+	//Assert
+	assert.FileExists(t, filepath.Join(dir, "oas", "createUser.json"))
+	assert.FileExists(t, filepath.Join(dir, "oas", "createUser-2.json"))
+}
+
+func Test_WhenOutputFormatIsUnknown_ShouldError(t *testing.T) {
+	//Act
+	err := output.ValidateFormats([]string{"csv"})
+
+	//Assert
+	assert.EqualError(t, err, `unknown output format "csv", use json, ndjson, har or per-operation`)
+}
@@ -0,0 +1,189 @@
+package planner_tests
+
+import (
+	"testing"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/planner"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const serverURL = "http://localhost:8080"
+
+const shopOAS = `
+openapi: 3.0.0
+info:
+  title: Shop API
+paths:
+  /users/{user_id}/orders/{orderId}:
+    delete:
+      responses:
+        '204':
+          description: Deleted
+    get:
+      responses:
+        '200':
+          description: OK
+  /users/{user_id}:
+    delete:
+      responses:
+        '204':
+          description: Deleted
+    put:
+      responses:
+        '200':
+          description: OK
+  /users/{user_id}/orders:
+    post:
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id:
+                    type: string
+                  total:
+                    type: number
+  /users:
+    post:
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+  /health:
+    get:
+      responses:
+        '200':
+          description: OK
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        userId:
+          type: string
+        name:
+          type: string
+`
+
+func parseOAS(t *testing.T, content string) oas_struct.OAS {
+	t.Helper()
+
+	var oas oas_struct.OAS
+	require.NoError(t, yaml.Unmarshal([]byte(content), &oas))
+	return oas
+}
+
+func stepNames(plan *planner.Plan) []string {
+	var names []string
+	for _, step := range plan.Steps {
+		names = append(names, step.Operation.Method+" "+step.Operation.Path)
+	}
+	return names
+}
+
+func Test_WhenPlanningCRUDOperations_ShouldCreateFirstAndDeleteChildrenBeforeParents(t *testing.T) {
+	//Arrange
+	oas := parseOAS(t, shopOAS)
+
+	//Act
+	plan := planner.New(oas, serverURL)
+
+	//Assert
+	assert.Equal(t, []string{
+		"POST /users",
+		"POST /users/{user_id}/orders",
+		"GET /health",
+		"GET /users/{user_id}/orders/{orderId}",
+		"PUT /users/{user_id}",
+		"DELETE /users/{user_id}/orders/{orderId}",
+		"DELETE /users/{user_id}",
+	}, stepNames(plan))
+}
+
+func Test_WhenAPathParamMatchesAResponseField_ShouldTakeItFromTheCreate(t *testing.T) {
+	//Arrange
+	oas := parseOAS(t, shopOAS)
+
+	//Act
+	plan := planner.New(oas, serverURL)
+
+	//Assert
+	var inputs []planner.Input
+	for _, step := range plan.Steps {
+		if step.Operation.Method == "GET" && step.Operation.Path == "/users/{user_id}/orders/{orderId}" {
+			inputs = step.Inputs
+		}
+	}
+	require.Len(t, inputs, 2)
+	assert.Equal(t, "user_id", inputs[0].Param)
+	assert.Equal(t, "/users", inputs[0].Producer.Path)
+	assert.Equal(t, "/userId", inputs[0].Pointer)
+	assert.Equal(t, "orderId", inputs[1].Param)
+	assert.Equal(t, "/users/{user_id}/orders", inputs[1].Producer.Path)
+	assert.Equal(t, "/id", inputs[1].Pointer)
+}
+
+func Test_WhenACreateSucceeds_ShouldFillItsIdIntoLaterRequests(t *testing.T) {
+	//Arrange
+	oas := parseOAS(t, shopOAS)
+	plan := planner.New(oas, serverURL)
+
+	create := request_sender.Request{Method: "POST", Url: serverURL + "/users"}
+	read := request_sender.Request{Method: "GET", Url: serverURL + "/users/{user_id}/orders/{orderId}"}
+
+	//Act
+	plan.Record(create, request_sender.Response{StatusCode: 201, Body: `{"userId": "u 1", "name": "Alex"}`})
+	filled := plan.Fill(read)
+
+	//Assert
+	assert.Equal(t, serverURL+"/users/u%201/orders/{orderId}", filled.Url)
+}
+
+func Test_WhenACreateFails_ShouldNotRecordItsResponse(t *testing.T) {
+	//Arrange
+	oas := parseOAS(t, shopOAS)
+	plan := planner.New(oas, serverURL)
+
+	create := request_sender.Request{Method: "POST", Url: serverURL + "/users"}
+	update := request_sender.Request{Method: "PUT", Url: serverURL + "/users/{user_id}"}
+
+	//Act
+	plan.Record(create, request_sender.Response{StatusCode: 400, Body: `{"userId": "u1"}`})
+	filled := plan.Fill(update)
+
+	//Assert
+	assert.Equal(t, update.Url, filled.Url)
+}
+
+func Test_WhenOrderingRequests_ShouldFollowThePlanAndKeepUnknownRequestsLast(t *testing.T) {
+	//Arrange
+	oas := parseOAS(t, shopOAS)
+	plan := planner.New(oas, serverURL)
+
+	requests := []request_sender.Request{
+		{Method: "DELETE", Url: serverURL + "/users/{user_id}"},
+		{Method: "GET", Url: "http://elsewhere/ping"},
+		{Method: "PUT", Url: serverURL + "/users/{user_id}"},
+		{Method: "POST", Url: serverURL + "/users"},
+	}
+
+	//Act
+	ordered := plan.Order(requests)
+
+	//Assert
+	var methods []string
+	for _, req := range ordered {
+		methods = append(methods, req.Method)
+	}
+	assert.Equal(t, []string{"POST", "PUT", "DELETE", "GET"}, methods)
+}
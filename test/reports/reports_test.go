@@ -0,0 +1,101 @@
+package reports_tests
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alexplayer15/parmesan/reports"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleResults() []results.Result {
+	return []results.Result{
+		{
+			Request:   request_sender.Request{Method: "GET", Url: "http://localhost:8080/users"},
+			Response:  request_sender.Response{StatusCode: 200, Timing: request_sender.Timing{Total: 20 * time.Millisecond}},
+			Operation: "listUsers",
+		},
+		{
+			Request:    request_sender.Request{Method: "POST", Url: "http://localhost:8080/users", Body: `{"name": "<script>"}`},
+			Response:   request_sender.Response{StatusCode: 500, Headers: http.Header{"Content-Type": {"application/json"}}},
+			Operation:  "createUser",
+			Violations: []string{"status: status code 500 is not declared for this operation"},
+			Failures:   []string{"status 500 is not 2xx"},
+		},
+		{
+			Request: request_sender.Request{Method: "DELETE", Url: "http://localhost:8080/users/1"},
+			Err:     fmt.Errorf("connection refused"),
+			Failures: []string{
+				"transport error: connection refused",
+			},
+		},
+	}
+}
+
+type junitCase struct {
+	Name    string `xml:"name,attr"`
+	Failure *struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+	} `xml:"failure"`
+}
+
+type junitSuites struct {
+	Tests    int `xml:"tests,attr"`
+	Failures int `xml:"failures,attr"`
+	Suites   []struct {
+		Skipped int         `xml:"skipped,attr"`
+		Cases   []junitCase `xml:"testcase"`
+	} `xml:"testsuite"`
+}
+
+func Test_WhenWritingJUnit_ShouldWriteATestCasePerResultWithFailures(t *testing.T) {
+	//Arrange
+	var buf bytes.Buffer
+
+	//Act
+	err := reports.WriteJUnit(&buf, "oas", sampleResults(), 2)
+
+	//Assert
+	require.NoError(t, err)
+	var parsed junitSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &parsed))
+
+	assert.Equal(t, 3, parsed.Tests)
+	assert.Equal(t, 2, parsed.Failures)
+	require.Len(t, parsed.Suites, 1)
+	assert.Equal(t, 2, parsed.Suites[0].Skipped)
+
+	cases := parsed.Suites[0].Cases
+	require.Len(t, cases, 3)
+	assert.Equal(t, "listUsers", cases[0].Name)
+	assert.Nil(t, cases[0].Failure)
+	assert.Equal(t, "status 500 is not 2xx", cases[1].Failure.Message)
+	assert.Equal(t, "SchemaViolation", cases[1].Failure.Type)
+	assert.Equal(t, "DELETE http://localhost:8080/users/1", cases[2].Name)
+	assert.Equal(t, "TransportError", cases[2].Failure.Type)
+}
+
+func Test_WhenWritingHTML_ShouldIncludeResultsAndEscapeContent(t *testing.T) {
+	//Arrange
+	var buf bytes.Buffer
+
+	//Act
+	err := reports.WriteHTML(&buf, "oas", sampleResults(), 0)
+
+	//Assert
+	require.NoError(t, err)
+	html := buf.String()
+	assert.Contains(t, html, "Failed: 2")
+	assert.Contains(t, html, "createUser")
+	assert.Contains(t, html, "status code 500 is not declared for this operation")
+	assert.Contains(t, html, "&lt;script&gt;")
+	assert.NotContains(t, html, "<script>")
+}
@@ -0,0 +1,213 @@
+package request_sender_tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenRequestsAreSeparatedByThreeHashes_ShouldParseEachRequest(t *testing.T) {
+	//Arrange
+	httpFile := `### List users
+GET http://localhost:8080/users
+
+### Delete a user
+DELETE http://localhost:8080/users/1
+`
+
+	//Act
+	requests, err := request_sender.ParseHttpRequestFile(httpFile)
+
+	//Assert
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, "List users", requests[0].Name)
+	assert.Equal(t, "GET", requests[0].Method)
+	assert.Equal(t, "DELETE", requests[1].Method)
+	assert.Equal(t, "http://localhost:8080/users/1", requests[1].Url)
+}
+
+func Test_WhenRequestHasNameTagAndComments_ShouldUseNameAndIgnoreComments(t *testing.T) {
+	//Arrange
+	httpFile := `###
+# @name listUsers
+// lists every user
+# another comment
+GET http://localhost:8080/users HTTP/1.1
+# comments between headers are ignored
+Accept: application/json
+`
+
+	//Act
+	requests, err := request_sender.ParseHttpRequestFile(httpFile)
+
+	//Assert
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "listUsers", requests[0].Name)
+	assert.Equal(t, "http://localhost:8080/users", requests[0].Url)
+	assert.Equal(t, map[string]string{"Accept": "application/json"}, requests[0].Headers)
+}
+
+func Test_WhenFileVariablesAreDeclared_ShouldSubstituteThemInURLHeadersAndBody(t *testing.T) {
+	//Arrange
+	httpFile := `@host = http://localhost:8080
+@base = {{host}}/api
+@token = abc123
+
+POST {{base}}/users
+Authorization: Bearer {{token}}
+
+{"token": "{{ token }}", "other": "{{unknown}}"}
+`
+
+	//Act
+	requests, err := request_sender.ParseHttpRequestFile(httpFile)
+
+	//Assert
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "http://localhost:8080/api/users", requests[0].Url)
+	assert.Equal(t, "Bearer abc123", requests[0].Headers["Authorization"])
+	assert.Equal(t, `{"token": "abc123", "other": "{{unknown}}"}`, requests[0].Body)
+}
+
+func Test_WhenURLHasQueryContinuationLines_ShouldAppendThemToURL(t *testing.T) {
+	//Arrange
+	httpFile := `GET http://localhost:8080/users
+    ?page=2
+    &size=10
+Accept: application/json
+`
+
+	//Act
+	requests, err := request_sender.ParseHttpRequestFile(httpFile)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/users?page=2&size=10", requests[0].Url)
+	assert.Equal(t, "application/json", requests[0].Headers["Accept"])
+}
+
+func Test_WhenBodyIsIndented_ShouldPreserveIndentation(t *testing.T) {
+	//Arrange
+	httpFile := "POST http://localhost:8080/users\nContent-Type: application/json\n\n{\n  \"name\": \"Alex\",\n    \"nested\": true\n}\n\n\n"
+
+	//Act
+	requests, err := request_sender.ParseHttpRequestFile(httpFile)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"name\": \"Alex\",\n    \"nested\": true\n}", requests[0].Body)
+}
+
+func Test_WhenBodyIncludesAFile_ShouldReadItRelativeToTheHttpFile(t *testing.T) {
+	//Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "user.json"), []byte(`{"name": "{{name}}"}`+"\n"), 0644))
+	httpFile := filepath.Join(dir, "requests.http")
+	content := `@name = Alex
+
+### raw include
+POST http://localhost:8080/users
+
+< ./user.json
+
+### include with variables
+POST http://localhost:8080/users
+
+<@ ./user.json
+`
+	require.NoError(t, os.WriteFile(httpFile, []byte(content), 0644))
+
+	//Act
+	requests, err := request_sender.ParseHttpFile(httpFile)
+
+	//Assert
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, `{"name": "{{name}}"}`, requests[0].Body)
+	assert.Equal(t, `{"name": "Alex"}`, requests[1].Body)
+}
+
+func Test_WhenBodyIsFollowedByResponseHandler_ShouldLeaveHandlerOutOfBody(t *testing.T) {
+	//Arrange
+	httpFile := `POST http://localhost:8080/users
+
+{"name": "Alex"}
+
+> {% client.global.set("id", response.body.id); %}
+`
+
+	//Act
+	requests, err := request_sender.ParseHttpRequestFile(httpFile)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, `{"name": "Alex"}`, requests[0].Body)
+}
+
+func Test_WhenRequestLineIsBareURL_ShouldDefaultToGet(t *testing.T) {
+	//Arrange
+	httpFile := "http://localhost:8080/users\n"
+
+	//Act
+	requests, err := request_sender.ParseHttpRequestFile(httpFile)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "GET", requests[0].Method)
+	assert.Equal(t, "http://localhost:8080/users", requests[0].Url)
+}
+
+func Test_WhenHeaderIsInvalid_ShouldReturnErrorWithLineNumber(t *testing.T) {
+	//Arrange
+	httpFile := `### first
+GET http://localhost:8080/users
+
+### second
+GET http://localhost:8080/users
+not a header
+`
+
+	//Act
+	_, err := request_sender.ParseHttpRequestFile(httpFile)
+
+	//Assert
+	var pe *errors.ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, 6, pe.Line)
+	assert.Contains(t, err.Error(), "line 6")
+}
+
+func Test_WhenMethodIsInvalid_ShouldReturnValidationErrorWithLineNumber(t *testing.T) {
+	//Arrange
+	httpFile := "# comment\n\nPASTA http://localhost:8080/users\n"
+
+	//Act
+	_, err := request_sender.ParseHttpRequestFile(httpFile)
+
+	//Assert
+	var pe *errors.ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, 3, pe.Line)
+	var ve *errors.ValidationError
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, errors.ErrCodeInvalidMethod, ve.Code)
+}
+
+func Test_WhenFileHasOnlyCommentsAndVariables_ShouldReturnEmptyFileError(t *testing.T) {
+	//Arrange
+	httpFile := "@host = http://localhost\n# nothing to send\n###\n"
+
+	//Act
+	_, err := request_sender.ParseHttpRequestFile(httpFile)
+
+	//Assert
+	assert.ErrorIs(t, err, errors.ErrEmptyHTTPFile)
+}
@@ -0,0 +1,83 @@
+package request_sender_tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenRateIsSet_ShouldPaceRequestsAfterBurst(t *testing.T) {
+	//Arrange
+	limiter, err := request_sender.NewRateLimiter(request_sender.RateLimitOptions{RequestsPerSecond: 20, Burst: 2})
+	require.NoError(t, err)
+
+	//Act
+	start := time.Now()
+	for range 4 {
+		limiter.Wait(context.Background(), "http://localhost:8080/users")
+	}
+	elapsed := time.Since(start)
+
+	//Assert
+	//two requests go out immediately, the remaining two wait 50ms each
+	assert.GreaterOrEqual(t, elapsed, 90*time.Millisecond)
+}
+
+func Test_WhenDelayIsSet_ShouldWaitBetweenEveryRequest(t *testing.T) {
+	//Arrange
+	limiter, err := request_sender.NewRateLimiter(request_sender.RateLimitOptions{Burst: 1, Delay: 30 * time.Millisecond})
+	require.NoError(t, err)
+
+	//Act
+	start := time.Now()
+	for range 3 {
+		limiter.Wait(context.Background(), "http://localhost:8080/users")
+	}
+	elapsed := time.Since(start)
+
+	//Assert
+	assert.GreaterOrEqual(t, elapsed, 60*time.Millisecond)
+}
+
+func Test_WhenRateIsPerHost_ShouldNotPaceDifferentHostsTogether(t *testing.T) {
+	//Arrange
+	limiter, err := request_sender.NewRateLimiter(request_sender.RateLimitOptions{RequestsPerSecond: 1, Burst: 1, PerHost: true})
+	require.NoError(t, err)
+
+	//Act
+	start := time.Now()
+	limiter.Wait(context.Background(), "http://one.example.com/users")
+	limiter.Wait(context.Background(), "http://two.example.com/users")
+	elapsed := time.Since(start)
+
+	//Assert
+	assert.Less(t, elapsed, 500*time.Millisecond)
+}
+
+func Test_WhenBurstIsLessThanOne_ShouldError(t *testing.T) {
+	//Act
+	_, err := request_sender.NewRateLimiter(request_sender.RateLimitOptions{RequestsPerSecond: 5, Burst: 0})
+
+	//Assert
+	assert.EqualError(t, err, "burst must be at least 1, got 0")
+}
+
+func Test_WhenContextIsCancelledWhileWaiting_ShouldReturnPromptly(t *testing.T) {
+	//Arrange
+	limiter, err := request_sender.NewRateLimiter(request_sender.RateLimitOptions{Burst: 1, Delay: time.Hour})
+	require.NoError(t, err)
+	require.NoError(t, limiter.Wait(context.Background(), "http://localhost:8080/users"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	//Act
+	err = limiter.Wait(ctx, "http://localhost:8080/users")
+
+	//Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
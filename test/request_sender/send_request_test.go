@@ -0,0 +1,40 @@
+package request_sender_tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenRequestIsSent_ShouldCaptureResponseDetailsAndTiming(t *testing.T) {
+	//Arrange
+	var receivedHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeader = r.Header.Get("X-Tenant")
+		w.Header().Set("X-Request-Id", "abc")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	req := request_sender.Request{Method: "POST", Url: server.URL, Headers: map[string]string{"X-Tenant": "acme"}, Body: `{}`}
+
+	//Act
+	resp, err := request_sender.SendHTTPRequest(context.Background(), http.DefaultClient, req)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "acme", receivedHeader)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "abc", resp.Headers.Get("X-Request-Id"))
+	assert.Equal(t, "HTTP/1.1", resp.Proto)
+	assert.Equal(t, int64(8), resp.ContentLength)
+	assert.Positive(t, resp.Timing.Total)
+	assert.Positive(t, resp.Timing.TTFB)
+	assert.LessOrEqual(t, resp.Timing.TTFB, resp.Timing.Total)
+}
@@ -0,0 +1,101 @@
+package request_sender_tests
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/errors"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTLSTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_WhenServerUsesUntrustedCertificate_ShouldFailToSend(t *testing.T) {
+	//Arrange
+	server := newTLSTestServer(t)
+	client, err := request_sender.NewHTTPClient(request_sender.TransportOptions{})
+	require.NoError(t, err)
+
+	//Act
+	_, err = request_sender.SendHTTPRequest(context.Background(), client, request_sender.Request{Method: "GET", Url: server.URL})
+
+	//Assert
+	assert.Error(t, err)
+}
+
+func Test_WhenInsecureIsSet_ShouldSkipCertificateVerification(t *testing.T) {
+	//Arrange
+	server := newTLSTestServer(t)
+	client, err := request_sender.NewHTTPClient(request_sender.TransportOptions{Insecure: true})
+	require.NoError(t, err)
+
+	//Act
+	resp, err := request_sender.SendHTTPRequest(context.Background(), client, request_sender.Request{Method: "GET", Url: server.URL})
+
+	//Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func Test_WhenCABundleContainsServerCertificate_ShouldTrustServer(t *testing.T) {
+	//Arrange
+	server := newTLSTestServer(t)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0644))
+
+	client, err := request_sender.NewHTTPClient(request_sender.TransportOptions{CACertFile: caFile})
+	require.NoError(t, err)
+
+	//Act
+	resp, err := request_sender.SendHTTPRequest(context.Background(), client, request_sender.Request{Method: "GET", Url: server.URL})
+
+	//Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"ok":true}`, resp.Body)
+}
+
+func Test_WhenMinTLSVersionIsInvalid_ShouldReturnValidationError(t *testing.T) {
+	//Act
+	_, err := request_sender.NewHTTPClient(request_sender.TransportOptions{MinTLSVersion: "2.0"})
+
+	//Assert
+	var ve *errors.ValidationError
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, "tls-min-version", ve.Param)
+	assert.Equal(t, errors.ErrCodeInvalidTLS, ve.Code)
+}
+
+func Test_WhenProxyURLIsNotAbsolute_ShouldReturnValidationError(t *testing.T) {
+	//Act
+	_, err := request_sender.NewHTTPClient(request_sender.TransportOptions{ProxyURL: "proxy:3128"})
+
+	//Assert
+	var ve *errors.ValidationError
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, errors.ErrCodeInvalidProxy, ve.Code)
+}
+
+func Test_WhenOnlyClientCertificateIsGiven_ShouldError(t *testing.T) {
+	//Act
+	_, err := request_sender.NewHTTPClient(request_sender.TransportOptions{ClientCertFile: "client.pem"})
+
+	//Assert
+	assert.EqualError(t, err, "both a client certificate and a client key must be provided for mutual TLS")
+}
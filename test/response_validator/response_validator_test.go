@@ -0,0 +1,144 @@
+package response_validator_tests
+
+import (
+	"net/http"
+	"testing"
+
+	oas_struct "github.com/alexplayer15/parmesan/data"
+	"github.com/alexplayer15/parmesan/operations"
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/response_validator"
+	test_data "github.com/alexplayer15/parmesan/test_oas_data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func userResponseOAS() oas_struct.OAS {
+	oas := test_data.BaseOAS()
+	oas.Components.Schemas["User"] = oas_struct.Schema{
+		Type:     "object",
+		Required: []string{"id", "email"},
+		Properties: map[string]oas_struct.Property{
+			"id":     {Type: "integer"},
+			"email":  {Type: "string", Format: "email"},
+			"status": {Type: "string", Enum: []any{"active", "disabled"}},
+		},
+		AdditionalProperties: &oas_struct.AdditionalProperties{Allowed: false},
+	}
+
+	method := oas.Paths["/users"]["post"]
+	method.OperationId = "createUser"
+	method.Responses = map[string]oas_struct.Response{
+		"201": {
+			Headers: map[string]oas_struct.Header{
+				"Location": {Required: true, Schema: oas_struct.Schema{Type: "string"}},
+			},
+			Content: map[string]oas_struct.Content{
+				"application/json": {Schema: oas_struct.Schema{Ref: "#/components/schemas/User"}},
+			},
+		},
+		"4XX": {Description: "Client error"},
+	}
+	oas.Paths["/users"]["post"] = method
+
+	return oas
+}
+
+func validate(t *testing.T, oas oas_struct.OAS, resp request_sender.Response) []string {
+	t.Helper()
+	op, ok := operations.FindOperation(oas, oas.Servers[0].URL, "POST", "http://example.com/users")
+	require.True(t, ok)
+
+	var violations []string
+	for _, violation := range response_validator.ValidateResponse(oas, op, resp) {
+		violations = append(violations, violation.String())
+	}
+	return violations
+}
+
+func jsonResponse(status int, body string) request_sender.Response {
+	return request_sender.Response{
+		StatusCode: status,
+		Headers:    http.Header{"Content-Type": {"application/json"}, "Location": {"/users/1"}},
+		Body:       body,
+	}
+}
+
+func Test_WhenResponseConformsToSchema_ShouldReturnNoViolations(t *testing.T) {
+	//Arrange
+	oas := userResponseOAS()
+
+	//Act
+	violations := validate(t, oas, jsonResponse(201, `{"id": 1, "email": "alex@example.com", "status": "active"}`))
+
+	//Assert
+	assert.Empty(t, violations)
+}
+
+func Test_WhenStatusCodeIsNotDeclared_ShouldReportStatusViolation(t *testing.T) {
+	//Arrange
+	oas := userResponseOAS()
+
+	//Act
+	violations := validate(t, oas, jsonResponse(500, `{}`))
+
+	//Assert
+	assert.Equal(t, []string{"status: status code 500 is not declared for this operation"}, violations)
+}
+
+func Test_WhenStatusCodeIsCoveredByRange_ShouldMatchRange(t *testing.T) {
+	//Arrange
+	oas := userResponseOAS()
+	resp := request_sender.Response{StatusCode: 404, Headers: http.Header{}}
+
+	//Act
+	violations := validate(t, oas, resp)
+
+	//Assert
+	assert.Empty(t, violations)
+}
+
+func Test_WhenBodyBreaksSchema_ShouldReportEachViolationWithItsPath(t *testing.T) {
+	//Arrange
+	oas := userResponseOAS()
+
+	//Act
+	violations := validate(t, oas, jsonResponse(201, `{"id": 1.5, "email": "not-an-email", "status": "deleted", "extra": true}`))
+
+	//Assert
+	assert.ElementsMatch(t, []string{
+		`body.email: "not-an-email" is not a valid email`,
+		"body.extra: property is not defined in the schema and additionalProperties is false",
+		"body.id: expected integer, got number",
+		"body.status: value deleted is not one of the allowed values [active disabled]",
+	}, violations)
+}
+
+func Test_WhenRequiredFieldAndHeaderAreMissing_ShouldReportBoth(t *testing.T) {
+	//Arrange
+	oas := userResponseOAS()
+	resp := jsonResponse(201, `{"id": 1}`)
+	resp.Headers.Del("Location")
+
+	//Act
+	violations := validate(t, oas, resp)
+
+	//Assert
+	assert.ElementsMatch(t, []string{
+		"header Location: required response header is missing",
+		"body.email: required property is missing",
+	}, violations)
+}
+
+func Test_WhenContentTypeIsNotDeclared_ShouldReportContentTypeViolation(t *testing.T) {
+	//Arrange
+	oas := userResponseOAS()
+	resp := jsonResponse(201, `<user/>`)
+	resp.Headers.Set("Content-Type", "application/xml")
+
+	//Act
+	violations := validate(t, oas, resp)
+
+	//Assert
+	assert.Equal(t, []string{"header Content-Type: application/xml is not declared, expected one of application/json"}, violations)
+}
@@ -0,0 +1,55 @@
+package results_tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func slowResult() results.Result {
+	return results.Result{Response: request_sender.Response{StatusCode: 200, Timing: request_sender.Timing{Total: 2 * time.Second}}}
+}
+
+func Test_WhenFailingOnLatency_ShouldFailSlowResponses(t *testing.T) {
+	//Arrange
+	criteria, err := results.ParseCriteria([]string{results.CriterionNonSuccess, results.CriterionLatency}, time.Second)
+	require.NoError(t, err)
+
+	//Act
+	failures := criteria.Evaluate(slowResult())
+
+	//Assert
+	assert.Equal(t, []string{"took 2s, over the 1s limit"}, failures)
+}
+
+func Test_WhenMaxLatencyIsSetWithoutTheLatencyCriterion_ShouldError(t *testing.T) {
+	//Act
+	_, err := results.ParseCriteria([]string{results.CriterionNonSuccess}, time.Second)
+
+	//Assert
+	assert.EqualError(t, err, "max-latency only fails requests when fail-on includes latency")
+}
+
+func Test_WhenFailingOnLatencyWithoutMaxLatency_ShouldError(t *testing.T) {
+	//Act
+	_, err := results.ParseCriteria([]string{results.CriterionLatency}, 0)
+
+	//Assert
+	assert.EqualError(t, err, "fail-on latency requires --max-latency to be set")
+}
+
+func Test_WhenNotFailingOnLatency_ShouldPassSlowResponses(t *testing.T) {
+	//Arrange
+	criteria, err := results.ParseCriteria([]string{results.CriterionNonSuccess}, 0)
+	require.NoError(t, err)
+
+	//Act
+	failures := criteria.Evaluate(slowResult())
+
+	//Assert
+	assert.Empty(t, failures)
+}
@@ -0,0 +1,36 @@
+package results_tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/results"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WhenResultIsRedacted_ShouldReplaceSecretHeadersInSavedResponse(t *testing.T) {
+	//Arrange
+	result := results.Result{
+		Request: request_sender.Request{
+			Method:  "GET",
+			Url:     "http://localhost:8080/users",
+			Headers: map[string]string{"authorization": "Bearer secret", "X-Tenant": "acme"},
+		},
+		Response: request_sender.Response{
+			StatusCode: 200,
+			Headers:    http.Header{"Set-Cookie": {"session=secret"}},
+			Body:       `{"id": 1}`,
+		},
+	}
+
+	//Act
+	saved := results.NewSavedResponse(result.Redacted(results.DefaultRedactedHeaders))
+
+	//Assert
+	assert.Equal(t, results.RedactedValue, saved.Request.Headers["authorization"])
+	assert.Equal(t, "acme", saved.Request.Headers["X-Tenant"])
+	assert.Equal(t, []string{results.RedactedValue}, saved.Headers["Set-Cookie"])
+	assert.Equal(t, "Bearer secret", result.Request.Headers["authorization"], "original result should not be modified")
+	assert.Equal(t, map[string]any{"id": float64(1)}, saved.Response)
+}
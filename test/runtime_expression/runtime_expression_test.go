@@ -0,0 +1,81 @@
+package runtime_expression_tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/runtime_expression"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var exchange = runtime_expression.Exchange{
+	Request: request_sender.Request{
+		Method:  "POST",
+		Url:     "http://localhost/users/7?page=2",
+		Headers: map[string]string{"X-Tenant": "acme"},
+		Body:    `{"user": {"name": "Theo"}}`,
+	},
+	Response: request_sender.Response{
+		StatusCode: 201,
+		Headers:    http.Header{"Location": {"/users/42"}},
+		Body:       `{"id": 42, "tags": ["a", "b"]}`,
+	},
+	PathParams: map[string]string{"id": "7"},
+}
+
+func Test_WhenEvaluatingRuntimeExpressions_ShouldReadTheRequestAndResponse(t *testing.T) {
+	cases := map[string]any{
+		"$url":                      "http://localhost/users/7?page=2",
+		"$method":                   "POST",
+		"$statusCode":               201,
+		"$request.path.id":          "7",
+		"$request.query.page":       "2",
+		"$request.header.x-tenant":  "acme",
+		"$request.body#/user/name":  "Theo",
+		"$response.header.Location": "/users/42",
+		"$response.body#/id":        float64(42),
+		"$response.body#/tags/1":    "b",
+	}
+
+	for expr, expected := range cases {
+		//Arrange
+		parsed, err := runtime_expression.Parse(expr)
+		require.NoError(t, err, expr)
+
+		//Act
+		value, err := parsed.Evaluate(exchange)
+
+		//Assert
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, value, expr)
+	}
+}
+
+func Test_WhenRuntimeExpressionIsInvalid_ShouldError(t *testing.T) {
+	for _, expr := range []string{"$response.query.page", "$request", "$request.cookie", "$response.body#id"} {
+		//Act
+		_, err := runtime_expression.Parse(expr)
+
+		//Assert
+		assert.Error(t, err, expr)
+	}
+}
+
+func Test_WhenTemplateEmbedsExpressions_ShouldReplaceEachOne(t *testing.T) {
+	//Act
+	value, err := runtime_expression.EvaluateTemplate("/users/{$response.body#/id}/tags/{$response.body#/tags/0}", exchange)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "/users/42/tags/a", value)
+}
+
+func Test_WhenTemplateExpressionMatchesNothing_ShouldError(t *testing.T) {
+	//Act
+	_, err := runtime_expression.EvaluateTemplate("{$response.body#/missing}", exchange)
+
+	//Assert
+	assert.ErrorContains(t, err, "$response.body#/missing")
+}
@@ -0,0 +1,184 @@
+package script_tests
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alexplayer15/parmesan/request_sender"
+	"github.com/alexplayer15/parmesan/script"
+	"github.com/alexplayer15/parmesan/variables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequest() request_sender.Request {
+	return request_sender.Request{
+		Name:    "create user",
+		Method:  "POST",
+		Url:     "http://localhost/users",
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    `{"name": "Alex"}`,
+	}
+}
+
+func Test_WhenScriptSignsTheBody_ShouldSendTheModifiedRequest(t *testing.T) {
+	//Arrange
+	source := `
+body = json.decode(request["body"])
+body["tenant"] = var("tenant")
+request["body"] = body
+request["headers"]["X-Signature"] = hash.hmac_sha256(var("secret"), json.encode(body))
+request["url"] += "?signed=true"
+`
+	s, err := script.Load(source, "", "test")
+	require.NoError(t, err)
+	env := script.Env{Lookup: variables.FromMap(map[string]string{"tenant": "acme", "secret": "key"})}
+
+	//Act
+	req, err := s.BeforeRequest(newRequest(), env)
+
+	//Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "Alex", "tenant": "acme"}`, req.Body)
+	assert.Equal(t, "http://localhost/users?signed=true", req.Url)
+	assert.Equal(t, "create user", req.Name)
+	assert.Regexp(t, `^[0-9a-f]{64}$`, req.Headers["X-Signature"])
+	assert.Equal(t, "application/json", req.Headers["Content-Type"])
+}
+
+func Test_WhenResponseScriptSetsVariables_ShouldPassThemOn(t *testing.T) {
+	//Arrange
+	source := `
+user = json.decode(response["body"])
+set_var("userId", user["id"])
+set_var("tags", user["tags"])
+set_var("slow", response["latency_ms"] > 100)
+set_var("type", response["headers"]["Content-Type"])
+`
+	s, err := script.Load(source, "", "test")
+	require.NoError(t, err)
+	resp := request_sender.Response{
+		StatusCode: 201,
+		Headers:    http.Header{"Content-Type": {"application/json"}},
+		Body:       `{"id": 42, "tags": ["a", "b"]}`,
+		Timing:     request_sender.Timing{Total: 50 * time.Millisecond},
+	}
+	set := map[string]any{}
+	env := script.Env{Set: func(name string, value any) { set[name] = value }}
+
+	//Act
+	err = s.AfterResponse(newRequest(), resp, env)
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"userId": int64(42), "tags": []any{"a", "b"}, "slow": false, "type": "application/json"}, set)
+}
+
+func Test_WhenResponseScriptCallsFail_ShouldReturnItsMessage(t *testing.T) {
+	//Arrange
+	s, err := script.Load(`if response["status"] != 200: fail("unexpected status", response["status"])`, "", "check")
+	require.NoError(t, err)
+
+	//Act
+	err = s.AfterResponse(newRequest(), request_sender.Response{StatusCode: 500}, script.Env{})
+
+	//Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "script check")
+	assert.Contains(t, err.Error(), "unexpected status 500")
+}
+
+func Test_WhenResponseScriptChangesTheResponse_ShouldFail(t *testing.T) {
+	//Arrange
+	s, err := script.Load(`response["status"] = 200`, "", "test")
+	require.NoError(t, err)
+
+	//Act
+	err = s.AfterResponse(newRequest(), request_sender.Response{StatusCode: 500}, script.Env{})
+
+	//Assert
+	assert.ErrorContains(t, err, "frozen")
+}
+
+func Test_WhenScriptIsInvalid_ShouldFailToLoad(t *testing.T) {
+	//Act
+	_, err := script.Load(`request["body"] = undefined_name`, "", "test")
+
+	//Assert
+	assert.ErrorContains(t, err, "undefined: undefined_name")
+}
+
+func Test_WhenScriptLoadsAModule_ShouldFail(t *testing.T) {
+	//Arrange
+	s, err := script.Load(`load("secrets.star", "token")`, "", "test")
+	require.NoError(t, err)
+
+	//Act
+	_, err = s.BeforeRequest(newRequest(), script.Env{})
+
+	//Assert
+	assert.ErrorContains(t, err, "scripts have no access to files")
+}
+
+func Test_WhenScriptNeverFinishes_ShouldStopIt(t *testing.T) {
+	//Arrange
+	source := `
+def spin():
+    for i in range(1000000000):
+        pass
+spin()
+`
+	s, err := script.Load(source, "", "test")
+	require.NoError(t, err)
+
+	//Act
+	_, err = s.BeforeRequest(newRequest(), script.Env{})
+
+	//Assert
+	assert.ErrorContains(t, err, "too many steps")
+}
+
+func Test_WhenScriptIsAStarFile_ShouldReadItRelativeToTheBaseDir(t *testing.T) {
+	//Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sign.star"), []byte(`request["headers"]["X-Checksum"] = hash.sha256(request["body"])`), 0644))
+
+	//Act
+	s, err := script.Load("sign.star", dir, "test")
+	require.NoError(t, err)
+	req, err := s.BeforeRequest(request_sender.Request{Method: "POST", Url: "http://localhost", Headers: map[string]string{}, Body: "abc"}, script.Env{})
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", req.Headers["X-Checksum"])
+}
+
+func Test_WhenScriptPrints_ShouldWriteToTheOutput(t *testing.T) {
+	//Arrange
+	s, err := script.Load(`print(base64.encode("parmesan"), var("missing", "none"))`, "", "test")
+	require.NoError(t, err)
+	var out bytes.Buffer
+
+	//Act
+	_, err = s.BeforeRequest(newRequest(), script.Env{Output: &out})
+
+	//Assert
+	require.NoError(t, err)
+	assert.Equal(t, "[test] cGFybWVzYW4= none\n", out.String())
+}
+
+func Test_WhenScriptSetsAHeaderToANumber_ShouldFail(t *testing.T) {
+	//Arrange
+	s, err := script.Load(`request["headers"]["X-Count"] = 1`, "", "test")
+	require.NoError(t, err)
+
+	//Act
+	_, err = s.BeforeRequest(newRequest(), script.Env{})
+
+	//Assert
+	assert.ErrorContains(t, err, `request["headers"] must map strings to strings`)
+}
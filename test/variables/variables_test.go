@@ -0,0 +1,99 @@
+package variables_tests
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/alexplayer15/parmesan/variables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenPlaceholderIsUnknown_ShouldLeaveItInPlace(t *testing.T) {
+	//Arrange
+	lookup := variables.FromMap(map[string]string{"host": "http://localhost"})
+
+	//Act
+	result := variables.Substitute("{{host}}/users/{{id}}", lookup)
+
+	//Assert
+	assert.Equal(t, "http://localhost/users/{{id}}", result)
+	assert.Equal(t, []string{"id"}, variables.Unresolved(result))
+}
+
+func Test_WhenVariablesReferToEachOther_ShouldResolveThem(t *testing.T) {
+	//Arrange
+	vars := map[string]string{"base": "{{host}}/api", "host": "http://localhost", "loop": "{{loop}}"}
+
+	//Act
+	resolved := variables.Resolve(vars)
+
+	//Assert
+	assert.Equal(t, "http://localhost/api", resolved["base"])
+	assert.Equal(t, "{{loop}}", resolved["loop"])
+}
+
+func Test_WhenUsingUUID_ShouldReturnANewVersion4UUIDEachTime(t *testing.T) {
+	//Act
+	first, ok := variables.Dynamic("$uuid")
+	second, _ := variables.Dynamic("$uuid")
+
+	//Assert
+	require.True(t, ok)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), first)
+	assert.NotEqual(t, first, second)
+}
+
+func Test_WhenUsingRandomIntWithRange_ShouldStayInsideIt(t *testing.T) {
+	for range 50 {
+		//Act
+		value, ok := variables.Dynamic("$randomInt 5 8")
+
+		//Assert
+		require.True(t, ok)
+		n, err := strconv.Atoi(value)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, n, 5)
+		assert.Less(t, n, 8)
+	}
+}
+
+func Test_WhenUsingEnvVariable_ShouldReadTheProcessEnvironment(t *testing.T) {
+	//Arrange
+	t.Setenv("PARMESAN_TEST_TENANT", "acme")
+
+	//Act
+	jetbrains, ok := variables.Dynamic("$env.PARMESAN_TEST_TENANT")
+	vscode, _ := variables.Dynamic("$processEnv PARMESAN_TEST_TENANT")
+	_, missing := variables.Dynamic("$env.PARMESAN_TEST_NOT_SET")
+
+	//Assert
+	assert.True(t, ok)
+	assert.Equal(t, "acme", jetbrains)
+	assert.Equal(t, "acme", vscode)
+	assert.False(t, missing)
+}
+
+func Test_WhenTimestampIsUsed_ShouldReturnUnixSeconds(t *testing.T) {
+	//Act
+	value, ok := variables.Dynamic("$timestamp")
+
+	//Assert
+	require.True(t, ok)
+	_, err := strconv.ParseInt(value, 10, 64)
+	assert.NoError(t, err)
+}
+
+func Test_WhenTextIsASinglePlaceholder_ShouldReturnItsName(t *testing.T) {
+	//Act
+	name, ok := variables.Placeholder(" {{ total }} ")
+	_, mixed := variables.Placeholder("x{{total}}")
+	_, several := variables.Placeholder("{{a}}{{b}}")
+
+	//Assert
+	assert.True(t, ok)
+	assert.Equal(t, "total", name)
+	assert.False(t, mixed)
+	assert.False(t, several)
+}
@@ -185,3 +185,231 @@ func SetupGenRequestTest(t *testing.T, testFileName string, testFileContentPath
 
 	return cmd, tmpDir
 }
+
+func SetupSendRequestTest(t *testing.T, oasContent string, args ...string) (*cobra.Command, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tmpDir, "oas.yml"), []byte(oasContent), 0644)
+	require.NoError(t, err, "failed to write test OAS file")
+
+	oldWd, err := os.Getwd()
+	require.NoError(t, err, "failed to get working directory")
+
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err, "failed to change directory")
+
+	t.Cleanup(func() {
+		os.Chdir(oldWd)
+	})
+
+	cmd := commands.NewRootCmd()
+
+	finalArgs := append([]string{"send-request", "oas.yml"}, args...)
+	cmd.SetArgs(finalArgs)
+
+	return cmd, tmpDir
+}
+
+// SendRequestOAS returns a minimal OAS with a single GET /users operation pointed at serverURL.
+func SendRequestOAS(serverURL string) string {
+	return fmt.Sprintf(`openapi: 3.0.0
+info:
+  title: Send Request Test API
+  version: 1.0.0
+servers:
+  - url: %s
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id:
+                    type: integer
+`, serverURL)
+}
+
+func SetupSendFileTest(t *testing.T, httpFileName string, httpFileContent string, args ...string) (*cobra.Command, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tmpDir, httpFileName), []byte(httpFileContent), 0644)
+	require.NoError(t, err, "failed to write test HTTP file")
+
+	oldWd, err := os.Getwd()
+	require.NoError(t, err, "failed to get working directory")
+
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err, "failed to change directory")
+
+	t.Cleanup(func() {
+		os.Chdir(oldWd)
+	})
+
+	cmd := commands.NewRootCmd()
+
+	finalArgs := append([]string{"send-file", httpFileName}, args...)
+	cmd.SetArgs(finalArgs)
+
+	return cmd, tmpDir
+}
+
+// SetupChainRequestTest runs chain-request on oas.yml and workflow.yml. An empty
+// workflowContent leaves the workflow file out, for chaining by links.
+func SetupChainRequestTest(t *testing.T, oasContent string, workflowContent string, args ...string) (*cobra.Command, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tmpDir, "oas.yml"), []byte(oasContent), 0644)
+	require.NoError(t, err, "failed to write test OAS file")
+
+	commandArgs := []string{"chain-request", "oas.yml"}
+	if workflowContent != "" {
+		err = os.WriteFile(filepath.Join(tmpDir, "workflow.yml"), []byte(workflowContent), 0644)
+		require.NoError(t, err, "failed to write test workflow file")
+		commandArgs = append(commandArgs, "workflow.yml")
+	}
+
+	oldWd, err := os.Getwd()
+	require.NoError(t, err, "failed to get working directory")
+
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err, "failed to change directory")
+
+	t.Cleanup(func() {
+		os.Chdir(oldWd)
+	})
+
+	cmd := commands.NewRootCmd()
+
+	finalArgs := append(commandArgs, args...)
+	cmd.SetArgs(finalArgs)
+
+	return cmd, tmpDir
+}
+
+// UsersCRUDOAS returns an OAS with create, list, get, update and delete operations
+// on /users pointed at serverURL.
+func UsersCRUDOAS(serverURL string) string {
+	return fmt.Sprintf(`openapi: 3.0.0
+info:
+  title: Users API
+  version: 1.0.0
+servers:
+  - url: %s
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        '200':
+          description: OK
+    post:
+      operationId: createUser
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/NewUser'
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+  /users/{id}:
+    get:
+      operationId: getUser
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        '200':
+          description: OK
+    patch:
+      operationId: updateUser
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/NewUser'
+      responses:
+        '200':
+          description: OK
+    delete:
+      operationId: deleteUser
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        '204':
+          description: Deleted
+components:
+  schemas:
+    NewUser:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Alex
+    User:
+      type: object
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+`, serverURL)
+}
+
+// SetupHooksLintTest runs hooks lint on oas.yml and hooks.yml.
+func SetupHooksLintTest(t *testing.T, oasContent string, hooksContent string) (*cobra.Command, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tmpDir, "oas.yml"), []byte(oasContent), 0644)
+	require.NoError(t, err, "failed to write test OAS file")
+
+	err = os.WriteFile(filepath.Join(tmpDir, "hooks.yml"), []byte(hooksContent), 0644)
+	require.NoError(t, err, "failed to write test hooks file")
+
+	oldWd, err := os.Getwd()
+	require.NoError(t, err, "failed to get working directory")
+
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err, "failed to change directory")
+
+	t.Cleanup(func() {
+		os.Chdir(oldWd)
+	})
+
+	cmd := commands.NewRootCmd()
+	cmd.SetArgs([]string{"hooks", "lint", "oas.yml", "hooks.yml"})
+
+	return cmd, tmpDir
+}
@@ -0,0 +1,111 @@
+package variables
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRandomIntMax matches the JetBrains HTTP Client, where {{$randomInt}}
+// is a number from 0 to 1000.
+const defaultRandomIntMax = 1000
+
+// Dynamic resolves the built-in variables shared by both editor clients. Each
+// call produces a fresh value, so two {{$uuid}} in one request differ.
+//
+//	{{$uuid}}              random UUID v4
+//	{{$timestamp}}         Unix timestamp in seconds
+//	{{$isoTimestamp}}      current UTC time in RFC 3339
+//	{{$randomInt}}         random integer from 0 to 1000
+//	{{$randomInt 5 10}}    random integer from 5 up to, not including, 10
+//	{{$env.NAME}}          process environment variable NAME
+//	{{$processEnv NAME}}   the same, in VS Code REST Client syntax
+func Dynamic(name string) (string, bool) {
+	if !strings.HasPrefix(name, "$") {
+		return "", false
+	}
+
+	fields := strings.Fields(name)
+	switch {
+	case name == "$uuid" || name == "$random.uuid":
+		return NewUUID(), true
+	case name == "$timestamp":
+		return strconv.FormatInt(time.Now().Unix(), 10), true
+	case name == "$isoTimestamp":
+		return time.Now().UTC().Format(time.RFC3339), true
+	case fields[0] == "$randomInt":
+		return randomInt(fields[1:])
+	case strings.HasPrefix(name, "$env."):
+		return os.LookupEnv(strings.TrimPrefix(name, "$env."))
+	case fields[0] == "$processEnv" && len(fields) == 2:
+		return os.LookupEnv(fields[1])
+	}
+
+	return "", false
+}
+
+// Chain returns a Lookup that asks each lookup in turn and uses the first
+// value found.
+func Chain(lookups ...Lookup) Lookup {
+	return func(name string) (string, bool) {
+		for _, lookup := range lookups {
+			if value, ok := lookup(name); ok {
+				return value, true
+			}
+		}
+		return "", false
+	}
+}
+
+func randomInt(args []string) (string, bool) {
+	var minimum, maximum int64 = 0, defaultRandomIntMax + 1
+
+	if len(args) != 0 {
+		if len(args) != 2 {
+			return "", false
+		}
+		var err error
+		if minimum, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+			return "", false
+		}
+		if maximum, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return "", false
+		}
+	}
+
+	n, err := RandomInt(minimum, maximum)
+	if err != nil {
+		return "", false
+	}
+
+	return strconv.FormatInt(n, 10), true
+}
+
+// RandomInt returns a random integer from minimum up to, not including, maximum.
+func RandomInt(minimum int64, maximum int64) (int64, error) {
+	if maximum <= minimum {
+		return 0, fmt.Errorf("the maximum %d must be greater than the minimum %d", maximum, minimum)
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(maximum-minimum))
+	if err != nil {
+		return 0, err
+	}
+
+	return minimum + n.Int64(), nil
+}
+
+// NewUUID returns a random UUID v4.
+func NewUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40 //version 4
+	b[8] = (b[8] & 0x3f) | 0x80 //RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,111 @@
+package variables
+
+import (
+	"maps"
+	"regexp"
+	"strings"
+)
+
+// maxDepth bounds how many times variables referring to other variables are
+// expanded, so a cycle such as `@a = {{b}}` / `@b = {{a}}` cannot loop forever.
+const maxDepth = 10
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+// Lookup returns the value of a variable and whether it is known.
+type Lookup func(name string) (string, bool)
+
+// FromMap returns a Lookup backed by vars.
+func FromMap(vars map[string]string) Lookup {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+// Substitute replaces every {{name}} placeholder in text that lookup knows.
+// Unknown placeholders are left untouched so a later pass can fill them in.
+func Substitute(text string, lookup Lookup) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := lookup(name); ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// Resolve expands variables that refer to other variables in the same set.
+func Resolve(vars map[string]string) map[string]string {
+	resolved := maps.Clone(vars)
+
+	for range maxDepth {
+		changed := false
+		for name, value := range resolved {
+			expanded := Substitute(value, FromMap(resolved))
+			if expanded != value {
+				resolved[name] = expanded
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	return resolved
+}
+
+// Unresolved lists the names of the placeholders still present in text.
+func Unresolved(text string) []string {
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// Placeholder returns the name of the variable when text is nothing but a
+// single {{name}} placeholder, spaces included.
+func Placeholder(text string) (string, bool) {
+	match := placeholderPattern.FindStringSubmatchIndex(text)
+	if match == nil || strings.TrimSpace(text[:match[0]]) != "" || strings.TrimSpace(text[match[1]:]) != "" {
+		return "", false
+	}
+	return text[match[2]:match[3]], true
+}
+
+// ResolveValue substitutes placeholders in the strings of a decoded JSON or
+// YAML value. A string that is a single placeholder for a variable in typed
+// that is not a string becomes that value, so `count: "{{total}}"` sends a
+// number when total holds one.
+func ResolveValue(value any, typed map[string]any, lookup Lookup) any {
+	switch v := value.(type) {
+	case string:
+		if name, ok := Placeholder(v); ok {
+			if raw, ok := typed[name]; ok {
+				if _, isString := raw.(string); !isString {
+					return raw
+				}
+			}
+		}
+		return Substitute(v, lookup)
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, nested := range v {
+			resolved[key] = ResolveValue(nested, typed, lookup)
+		}
+		return resolved
+	case []any:
+		resolved := make([]any, len(v))
+		for i, nested := range v {
+			resolved[i] = ResolveValue(nested, typed, lookup)
+		}
+		return resolved
+	}
+	return value
+}